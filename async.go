@@ -0,0 +1,155 @@
+package llog
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// AsyncPolicy defines what an async logger does when its queue is full
+type AsyncPolicy int
+
+// AsyncPolicies
+const (
+	AsyncBlock AsyncPolicy = iota // wait for room in the queue, losing nothing
+	AsyncDrop                     // discard the line, never blocking the caller
+)
+
+var errAsyncClosed = errors.New("llog: async logger is closed")
+
+type asyncItem struct {
+	level   Level
+	leveled bool
+	p       []byte
+	done    chan struct{} // set on flush markers
+}
+
+// asyncWriter queues lines for a background goroutine that writes them out
+type asyncWriter struct {
+	out     io.Writer
+	policy  AsyncPolicy
+	onError func(error)
+
+	queue   chan asyncItem
+	stopped chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newAsyncWriter(out io.Writer, bufferSize int, policy AsyncPolicy, onError func(error)) *asyncWriter {
+	w := &asyncWriter{
+		out:     out,
+		policy:  policy,
+		onError: onError,
+		queue:   make(chan asyncItem, bufferSize),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+
+	for item := range w.queue {
+		if item.done != nil {
+			close(item.done)
+			continue
+		}
+
+		var err error
+		if item.leveled {
+			_, err = writeLevel(w.out, item.level, item.p)
+		} else {
+			_, err = w.out.Write(item.p)
+		}
+		if err != nil {
+			w.onError(err)
+		}
+	}
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	// p is a pooled buffer that is reused once Write returns
+	return w.enqueue(asyncItem{p: append([]byte(nil), p...)}, len(p))
+}
+
+func (w *asyncWriter) WriteLevel(level Level, p []byte) (int, error) {
+	return w.enqueue(asyncItem{
+		level:   level,
+		leveled: true,
+		p:       append([]byte(nil), p...),
+	}, len(p))
+}
+
+func (w *asyncWriter) enqueue(item asyncItem, n int) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return 0, errAsyncClosed
+	}
+
+	if w.policy == AsyncDrop {
+		select {
+		case w.queue <- item:
+		default:
+		}
+		return n, nil
+	}
+
+	w.queue <- item
+	return n, nil
+}
+
+// Flush blocks until every line queued before the call has been written,
+// then flushes the wrapped writer
+func (w *asyncWriter) Flush() error {
+	done := make(chan struct{})
+
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+	w.queue <- asyncItem{done: done}
+	w.mu.RUnlock()
+
+	<-done
+	return flushWriter(w.out)
+}
+
+// Close flushes the queue and stops the background goroutine. The wrapped
+// writer is left open since it's shared with the parent logger.
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.stopped
+	return nil
+}
+
+// WithAsync returns a logger that hands formatted lines to a background
+// goroutine through a queue of bufferSize lines, so logging calls don't
+// wait on the writer. When the queue is full the caller blocks with
+// AsyncBlock, or the line is silently dropped with AsyncDrop.
+//
+// Only the main output is asynchronous; lines routed by WithLevelOutput
+// are still written synchronously. Call Flush to wait for queued lines
+// and Close to stop the goroutine before the process exits.
+func (l *Logger) WithAsync(bufferSize int, policy AsyncPolicy) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.out = newMutexWriter(newAsyncWriter(l.out, bufferSize, policy, l.handleError))
+	return clone
+}
@@ -0,0 +1,184 @@
+package llog
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultBufferSize is used by NewBufferedWriter when maxBytes isn't positive
+const defaultBufferSize = 64 << 10
+
+// bufferedWriter batches writes until its buffer fills or a timer fires
+type bufferedWriter struct {
+	out      io.Writer
+	maxBytes int
+
+	mu     sync.Mutex
+	buf    []byte
+	err    error // last error of a background flush
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewBufferedWriter returns a writer that accumulates writes and passes
+// them to w once maxBytes are buffered or flushInterval has elapsed,
+// whichever comes first, to save syscalls on network sinks. An interval
+// that isn't positive disables timed flushes. Errors of timed flushes are
+// returned by the next Write or Flush. Close flushes what is buffered and
+// stops the timer but leaves w open.
+func NewBufferedWriter(w io.Writer, flushInterval time.Duration, maxBytes int) io.WriteCloser {
+	if maxBytes <= 0 {
+		maxBytes = defaultBufferSize
+	}
+
+	bw := &bufferedWriter{
+		out:      w,
+		maxBytes: maxBytes,
+		buf:      make([]byte, 0, maxBytes),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if flushInterval > 0 {
+		go bw.run(flushInterval)
+	} else {
+		close(bw.done)
+	}
+	return bw
+}
+
+func (w *bufferedWriter) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if err := w.flush(); err != nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// flush writes out the buffer, w.mu must be held
+func (w *bufferedWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	_, err := w.out.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// takeErr returns and clears the error of a background flush
+func (w *bufferedWriter) takeErr() error {
+	err := w.err
+	w.err = nil
+	return err
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if err := w.takeErr(); err != nil {
+		return 0, err
+	}
+
+	if len(w.buf) > 0 && len(w.buf)+len(p) > w.maxBytes {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.maxBytes {
+		if err := w.flush(); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *bufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.takeErr(); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+func (w *bufferedWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	default:
+		close(w.stop)
+		<-w.done
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.takeErr(); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+// lineFlushWriter flushes its writer after every completed line
+type lineFlushWriter struct {
+	w io.Writer
+}
+
+// NewLineFlushWriter returns a writer that calls Flush on w after each
+// write ending in a newline, which for a logger output means after every
+// line, so consumers of a buffered sink such as a bufio.Writer or one from
+// NewBufferedWriter see lines promptly. It trades the batching of w for
+// latency; leave w unwrapped where throughput matters more. Unlike the
+// logger's Flush it never falls back to Sync.
+func NewLineFlushWriter(w io.Writer) io.Writer {
+	return &lineFlushWriter{w: w}
+}
+
+func (w *lineFlushWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if f, ok := w.w.(flusher); ok && len(p) > 0 && p[len(p)-1] == '\n' {
+		return n, f.Flush()
+	}
+	return n, nil
+}
+
+func (w *lineFlushWriter) Flush() error {
+	return flushWriter(w.w)
+}
+
+func (w *lineFlushWriter) Close() error {
+	return closeWriter(w.w)
+}
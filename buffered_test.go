@@ -0,0 +1,49 @@
+package llog
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLineFlushWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(io.Writer) io.Writer
+	}{
+		{"bufio", func(w io.Writer) io.Writer { return bufio.NewWriterSize(w, 1<<16) }},
+		{"buffered", func(w io.Writer) io.Writer { return NewBufferedWriter(w, time.Hour, 1<<20) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sink bytes.Buffer
+			l := New(OptionOutput(NewLineFlushWriter(tt.wrap(&sink)))).WithTimeFormat("")
+
+			l.Info("first")
+			if got, want := sink.String(), "[I]first\n"; got != want {
+				t.Fatalf("sink after first line = %q, want %q", got, want)
+			}
+			l.Warn("second")
+			if got, want := sink.String(), "[I]first\n[W]second\n"; got != want {
+				t.Fatalf("sink after second line = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLineFlushWriterPartial(t *testing.T) {
+	var sink bytes.Buffer
+	bw := bufio.NewWriterSize(&sink, 1<<16)
+	w := NewLineFlushWriter(bw)
+
+	io.WriteString(w, "no newline yet")
+	if sink.Len() != 0 {
+		t.Fatalf("partial line flushed: %q", sink.String())
+	}
+	io.WriteString(w, "\n")
+	if got, want := sink.String(), "no newline yet\n"; got != want {
+		t.Fatalf("sink = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package llog
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCallerCache bounds the call sites kept by callerCache. Programs have
+// a limited number of them, so once full new sites are simply resolved
+// every time.
+const maxCallerCache = 4096
+
+type callerFrame struct {
+	file string
+	line int
+	fn   string
+}
+
+var (
+	callerCache     sync.Map // uintptr -> callerFrame
+	callerCacheSize atomic.Int64
+)
+
+// lookupCaller resolves pc to its frame, caching the result
+func lookupCaller(pc uintptr) callerFrame {
+	if f, ok := callerCache.Load(pc); ok {
+		return f.(callerFrame)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	f := callerFrame{
+		file: frame.File,
+		line: frame.Line,
+		fn:   frame.Function,
+	}
+	if callerCacheSize.Load() < maxCallerCache {
+		if _, loaded := callerCache.LoadOrStore(pc, f); !loaded {
+			callerCacheSize.Add(1)
+		}
+	}
+	return f
+}
+
+// WithCallerFilter returns a logger that reports the first caller whose
+// file skip returns false for, instead of a fixed number of frames up, so
+// wrapper packages are skipped however deeply they nest:
+//
+//	l = l.WithCallerFilter(func(file string) bool {
+//		return strings.Contains(file, "/internal/logging/")
+//	})
+//
+// The search starts at the frame WithCallerSkip points at and covers 32
+// frames; when all are skipped the first one is reported. A nil skip
+// removes the filter.
+func (l *Logger) WithCallerFilter(skip func(file string) bool) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.callerFilter = skip
+	return clone
+}
+
+// filteredCallerPC is callerPC applying the caller filter of l
+func (l *Logger) filteredCallerPC(skip int) uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	for _, pc := range pcs[:n] {
+		if !l.callerFilter(lookupCaller(pc).file) {
+			return pc
+		}
+	}
+	return pcs[0]
+}
@@ -0,0 +1,164 @@
+package llog_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nayotta/llog"
+)
+
+// thisLine returns the line it is called from
+func thisLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+var callerRe = regexp.MustCompile(`([^\s\]]+\.go):(\d+) `)
+
+// reportedCaller returns the file and line of the first line in out
+func reportedCaller(t *testing.T, out string) (string, int) {
+	t.Helper()
+	m := callerRe.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("no caller in %q", out)
+	}
+	line, _ := strconv.Atoi(m[2])
+	return m[1], line
+}
+
+// callerTestLogger returns a logger printing short callers to buf
+func callerTestLogger(buf *bytes.Buffer) *llog.Logger {
+	return llog.New(llog.OptionOutput(buf), llog.OptionFileAndLine(true)).WithShortCaller(true)
+}
+
+// logWrapped is a logging helper one level above the logger
+func logWrapped(l *llog.Logger, msg string) {
+	l.WithCallerSkip(1).Info(msg)
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	var buf bytes.Buffer
+	l := callerTestLogger(&buf)
+
+	want := thisLine() + 1
+	logWrapped(l, "wrapped")
+	file, line := reportedCaller(t, buf.String())
+	if file != "caller_test.go" || line != want {
+		t.Fatalf("caller = %s:%d, want caller_test.go:%d", file, line, want)
+	}
+}
+
+// TestCallerEntryPoints checks that every entry point reports the line it
+// is called from, the contract of callerDepth
+func TestCallerEntryPoints(t *testing.T) {
+	defer llog.SetExitFunc(nil)
+	llog.SetExitFunc(func(int) {})
+	defer llog.Reset()
+
+	tests := []struct {
+		name string
+		call func(l *llog.Logger) int
+	}{
+		{"Error", func(l *llog.Logger) int { l.Error("x"); return thisLine() }},
+		{"Errorf", func(l *llog.Logger) int { l.Errorf("%s", "x"); return thisLine() }},
+		{"Warn", func(l *llog.Logger) int { l.Warn("x"); return thisLine() }},
+		{"Warning", func(l *llog.Logger) int { l.Warning("x"); return thisLine() }},
+		{"Info", func(l *llog.Logger) int { l.Info("x"); return thisLine() }},
+		{"Infof", func(l *llog.Logger) int { l.Infof("%s", "x"); return thisLine() }},
+		{"Debug", func(l *llog.Logger) int { l.Debug("x"); return thisLine() }},
+		{"Trace", func(l *llog.Logger) int { l.Trace("x"); return thisLine() }},
+		{"Log", func(l *llog.Logger) int { l.Log(llog.LevelInfo, "x"); return thisLine() }},
+		{"Logf", func(l *llog.Logger) int { l.Logf(llog.LevelInfo, "%s", "x"); return thisLine() }},
+		{"Print", func(l *llog.Logger) int { l.Print("x"); return thisLine() }},
+		{"Println", func(l *llog.Logger) int { l.Println("x"); return thisLine() }},
+		{"Printf", func(l *llog.Logger) int { l.Printf("%s", "x"); return thisLine() }},
+		{"Fatal", func(l *llog.Logger) int { l.Fatal("x"); return thisLine() }},
+		{"Fatalf", func(l *llog.Logger) int { l.Fatalf("%s", "x"); return thisLine() }},
+		{"Panic", func(l *llog.Logger) (line int) {
+			defer func() { recover() }()
+			line = thisLine() + 1
+			l.Panic("x")
+			return
+		}},
+		{"InfoIf", func(l *llog.Logger) int { l.InfoIf(true, "x"); return thisLine() }},
+		{"InfofIf", func(l *llog.Logger) int { l.InfofIf(true, "%s", "x"); return thisLine() }},
+		{"InfoFunc", func(l *llog.Logger) int { l.InfoFunc(func() string { return "x" }); return thisLine() }},
+		{"InfoLazy", func(l *llog.Logger) int { l.InfoLazy(func() string { return "x" }); return thisLine() }},
+		{"Writer", func(l *llog.Logger) int { fmt.Fprintln(l.Writer(llog.LevelInfo), "x"); return thisLine() }},
+		{"Writer log.Logger", func(l *llog.Logger) int { log.New(l.Writer(llog.LevelInfo), "", 0).Print("x"); return thisLine() }},
+		{"DefaultWriter", func(l *llog.Logger) int { fmt.Fprintln(l.DefaultWriter(), "x"); return thisLine() }},
+		{"slog", func(l *llog.Logger) int { slog.New(llog.NewSlogHandler(l)).Info("x"); return thisLine() }},
+		{"WithTag", func(l *llog.Logger) int { l.WithTag("t").Info("x"); return thisLine() }},
+
+		{"package Error", func(*llog.Logger) int { llog.Error("x"); return thisLine() }},
+		{"package Warning", func(*llog.Logger) int { llog.Warning("x"); return thisLine() }},
+		{"package Info", func(*llog.Logger) int { llog.Info("x"); return thisLine() }},
+		{"package Infof", func(*llog.Logger) int { llog.Infof("%s", "x"); return thisLine() }},
+		{"package Log", func(*llog.Logger) int { llog.Log(llog.LevelInfo, "x"); return thisLine() }},
+		{"package Print", func(*llog.Logger) int { llog.Print("x"); return thisLine() }},
+		{"package Println", func(*llog.Logger) int { llog.Println("x"); return thisLine() }},
+		{"package Printf", func(*llog.Logger) int { llog.Printf("%s", "x"); return thisLine() }},
+		{"package Fatal", func(*llog.Logger) int { llog.Fatal("x"); return thisLine() }},
+		{"package Panic", func(*llog.Logger) (line int) {
+			defer func() { recover() }()
+			line = thisLine() + 1
+			llog.Panic("x")
+			return
+		}},
+		{"package InfoIf", func(*llog.Logger) int { llog.InfoIf(true, "x"); return thisLine() }},
+		{"package InfoFunc", func(*llog.Logger) int { llog.InfoFunc(func() string { return "x" }); return thisLine() }},
+		{"package InfoLazy", func(*llog.Logger) int { llog.InfoLazy(func() string { return "x" }); return thisLine() }},
+		{"package WithTag", func(*llog.Logger) int { llog.WithTag("t").Info("x"); return thisLine() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := callerTestLogger(&buf).WithLevel(llog.LevelTrace)
+			llog.SetDefault(l)
+
+			want := tt.call(l)
+			file, line := reportedCaller(t, buf.String())
+			if file != "caller_test.go" || line != want {
+				t.Fatalf("caller = %s:%d, want caller_test.go:%d", file, line, want)
+			}
+		})
+	}
+}
+
+func TestWithCallerFilter(t *testing.T) {
+	var buf bytes.Buffer
+	l := callerTestLogger(&buf).WithCallerFilter(func(file string) bool {
+		return strings.HasSuffix(file, "/wrapper_test.go")
+	})
+
+	want := thisLine() + 1
+	auditLog(l, "wrapped twice")
+	file, line := reportedCaller(t, buf.String())
+	if file != "caller_test.go" || line != want {
+		t.Fatalf("caller = %s:%d, want caller_test.go:%d", file, line, want)
+	}
+
+	buf.Reset()
+	want = thisLine() + 1
+	l.Info("direct")
+	if file, line := reportedCaller(t, buf.String()); file != "caller_test.go" || line != want {
+		t.Fatalf("direct caller = %s:%d, want caller_test.go:%d", file, line, want)
+	}
+}
+
+func TestWithCallerFilterUnfiltered(t *testing.T) {
+	var buf bytes.Buffer
+	l := callerTestLogger(&buf)
+
+	auditLog(l, "wrapped twice")
+	if file, _ := reportedCaller(t, buf.String()); file != "wrapper_test.go" {
+		t.Fatalf("caller = %s, want wrapper_test.go", file)
+	}
+}
@@ -0,0 +1,46 @@
+package llog
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// ChannelWriter delivers each written line to a channel, see
+// NewChannelWriter
+type ChannelWriter struct {
+	ch       chan<- string
+	blocking bool
+	dropped  atomic.Uint64
+}
+
+// NewChannelWriter returns a writer sending every write, one line as the
+// logger writes them, to ch without its trailing newline. When blocking is
+// false and ch is full the line is dropped and counted by Dropped;
+// otherwise Write waits for room. ch must not be closed while the writer
+// is in use.
+func NewChannelWriter(ch chan<- string, blocking bool) *ChannelWriter {
+	return &ChannelWriter{
+		ch:       ch,
+		blocking: blocking,
+	}
+}
+
+func (w *ChannelWriter) Write(p []byte) (int, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+	if w.blocking {
+		w.ch <- line
+		return len(p), nil
+	}
+
+	select {
+	case w.ch <- line:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns how many lines were dropped because ch was full
+func (w *ChannelWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
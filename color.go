@@ -0,0 +1,149 @@
+package llog
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// ColorMode defines when level tokens are colored
+type ColorMode int
+
+// ColorModes
+const (
+	ColorNever ColorMode = iota // default, plain output
+	ColorAuto                   // color only when writing to a terminal
+	ColorAlways
+)
+
+const colorReset = "\x1b[0m"
+
+var levelColors = map[Level]string{
+	LevelFatal: "\x1b[35m", // magenta
+	LevelError: "\x1b[31m", // red
+	LevelWarn:  "\x1b[33m", // yellow
+	LevelInfo:  "\x1b[32m", // green
+	LevelDebug: "\x1b[36m", // cyan
+	LevelTrace: "\x1b[90m", // gray
+}
+
+func levelColor(level Level) string {
+	if c, ok := levelColors[level]; ok {
+		return c
+	}
+	return "\x1b[37m"
+}
+
+// isTerminal reports whether w is an *os.File backed by a character device
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func (l *Logger) useColor(w *mutexWriter) bool {
+	switch l.color {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		return w.terminal
+	default:
+		return false
+	}
+}
+
+type stripState uint8
+
+const (
+	stripText stripState = iota
+	stripEsc             // after ESC
+	stripCSI             // inside ESC [ ... up to the final byte
+)
+
+// stripWriter removes ANSI escape sequences before writing
+type stripWriter struct {
+	w io.Writer
+
+	mu    sync.Mutex
+	state stripState
+	buf   []byte
+}
+
+// StripColor returns a writer that removes ANSI escape sequences such as
+// the level colors before passing the bytes on to w, for sinks like files
+// that share a colored logger with a terminal:
+//
+//	l = l.WithColor(llog.ColorAlways).WithOutput(llog.MultiWriter(os.Stderr, llog.StripColor(file)))
+//
+// Sequences split across writes are handled. Levels are passed on when w
+// is a LevelWriter.
+func StripColor(w io.Writer) io.Writer {
+	return &stripWriter{w: w}
+}
+
+func (s *stripWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b := s.strip(p); len(b) > 0 {
+		if _, err := s.w.Write(b); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (s *stripWriter) WriteLevel(level Level, p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b := s.strip(p); len(b) > 0 {
+		if _, err := writeLevel(s.w, level, b); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// strip returns p without escape sequences, carrying the parser state
+// over to the next call
+func (s *stripWriter) strip(p []byte) []byte {
+	s.buf = s.buf[:0]
+	for _, c := range p {
+		switch s.state {
+		case stripText:
+			if c == 0x1b {
+				s.state = stripEsc
+				continue
+			}
+			s.buf = append(s.buf, c)
+		case stripEsc:
+			if c == '[' {
+				s.state = stripCSI
+			} else {
+				// a two-byte sequence such as ESC c
+				s.state = stripText
+			}
+		case stripCSI:
+			if c >= 0x40 && c <= 0x7e {
+				s.state = stripText
+			}
+		}
+	}
+	return s.buf
+}
+
+func (s *stripWriter) Flush() error {
+	return flushWriter(s.w)
+}
+
+func (s *stripWriter) Close() error {
+	return closeWriter(s.w)
+}
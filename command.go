@@ -0,0 +1,34 @@
+package llog
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+)
+
+type commandOutput struct {
+	stdout, stderr *lineWriter
+}
+
+func (c *commandOutput) Close() error {
+	return errors.Join(c.stdout.Close(), c.stderr.Close())
+}
+
+// CommandLogger wires the stdout and stderr of cmd to l, tagged with tag,
+// so each line the child prints becomes an entry at infoLevel or errLevel.
+// Call it before cmd.Start, and close the returned Closer after cmd.Wait
+// to log a final line that didn't end in a newline:
+//
+//	out := l.CommandLogger(cmd, llog.LevelInfo, llog.LevelError, "git")
+//	err := cmd.Run()
+//	out.Close()
+func (l *Logger) CommandLogger(cmd *exec.Cmd, infoLevel, errLevel Level, tag string) io.Closer {
+	tagged := l.WithTag(tag)
+	c := &commandOutput{
+		stdout: &lineWriter{l: tagged, level: infoLevel},
+		stderr: &lineWriter{l: tagged, level: errLevel},
+	}
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+	return c
+}
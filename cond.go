@@ -0,0 +1,128 @@
+package llog
+
+import "fmt"
+
+// The If variants log only when cond is true and the level is enabled,
+// formatting nothing otherwise.
+// The Func variants call fn for the message only when the level is
+// enabled, for messages that are expensive to build:
+//
+//	l.DebugFunc(func() string { return dump(state) })
+//
+// Like the other entry points they call output directly, keeping the
+// reported caller right.
+
+func (l *Logger) ErrorIf(cond bool, v ...any) {
+	if cond && l.enabled(LevelError) {
+		l.output(LevelError, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) ErrorfIf(cond bool, format string, v ...any) {
+	if cond && l.enabled(LevelError) {
+		l.output(LevelError, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *Logger) ErrorFunc(fn func() string) {
+	l.outputFunc(LevelError, fn)
+}
+
+func (l *Logger) WarnIf(cond bool, v ...any) {
+	if cond && l.enabled(LevelWarn) {
+		l.output(LevelWarn, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) WarnfIf(cond bool, format string, v ...any) {
+	if cond && l.enabled(LevelWarn) {
+		l.output(LevelWarn, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *Logger) WarnFunc(fn func() string) {
+	l.outputFunc(LevelWarn, fn)
+}
+
+func (l *Logger) InfoIf(cond bool, v ...any) {
+	if cond && l.enabled(LevelInfo) {
+		l.output(LevelInfo, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) InfofIf(cond bool, format string, v ...any) {
+	if cond && l.enabled(LevelInfo) {
+		l.output(LevelInfo, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *Logger) InfoFunc(fn func() string) {
+	l.outputFunc(LevelInfo, fn)
+}
+
+func (l *Logger) DebugIf(cond bool, v ...any) {
+	if cond && l.enabled(LevelDebug) {
+		l.output(LevelDebug, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) DebugfIf(cond bool, format string, v ...any) {
+	if cond && l.enabled(LevelDebug) {
+		l.output(LevelDebug, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *Logger) DebugFunc(fn func() string) {
+	l.outputFunc(LevelDebug, fn)
+}
+
+func (l *Logger) TraceIf(cond bool, v ...any) {
+	if cond && l.enabled(LevelTrace) {
+		l.output(LevelTrace, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) TracefIf(cond bool, format string, v ...any) {
+	if cond && l.enabled(LevelTrace) {
+		l.output(LevelTrace, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *Logger) TraceFunc(fn func() string) {
+	l.outputFunc(LevelTrace, fn)
+}
+
+// The Lazy variants are aliases of the Func ones and share their
+// implementation. Either is the idiomatic way to guard expensive messages
+// without an explicit Enabled check:
+//
+//	l.DebugLazy(func() string { return expensiveDump() })
+//
+// costs nothing while debug is off. The plain methods defer formatting
+// the same way, so a fmt.Stringer argument's String method is also only
+// called for lines that are printed.
+
+// ErrorLazy is an alias of ErrorFunc
+func (l *Logger) ErrorLazy(fn func() string) {
+	l.outputFunc(LevelError, fn)
+}
+
+// WarnLazy is an alias of WarnFunc
+func (l *Logger) WarnLazy(fn func() string) {
+	l.outputFunc(LevelWarn, fn)
+}
+
+// InfoLazy is an alias of InfoFunc
+func (l *Logger) InfoLazy(fn func() string) {
+	l.outputFunc(LevelInfo, fn)
+}
+
+// DebugLazy is an alias of DebugFunc
+func (l *Logger) DebugLazy(fn func() string) {
+	l.outputFunc(LevelDebug, fn)
+}
+
+// TraceLazy is an alias of TraceFunc
+func (l *Logger) TraceLazy(fn func() string) {
+	l.outputFunc(LevelTrace, fn)
+}
@@ -0,0 +1,83 @@
+package llog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// countingStringer counts the calls to its String method
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "expensive"
+}
+
+func TestIf(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf), OptionLevel(LevelInfo))
+
+	var calls int
+	s := countingStringer{&calls}
+	l.InfoIf(false, s)
+	l.DebugIf(true, s)
+	l.DebugfIf(true, "%v", s)
+	if calls != 0 {
+		t.Fatalf("String called %d times for lines not printed", calls)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("output = %q", buf.String())
+	}
+
+	l.InfoIf(true, s)
+	l.ErrorfIf(true, "got %v", s)
+	if calls != 2 {
+		t.Fatalf("String called %d times, want 2", calls)
+	}
+	if got := buf.String(); !strings.Contains(got, "[I]expensive\n") || !strings.Contains(got, "[E]got expensive\n") {
+		t.Fatalf("output = %q", got)
+	}
+}
+
+func TestFunc(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf), OptionLevel(LevelInfo))
+
+	var calls int
+	fn := func() string {
+		calls++
+		return "built"
+	}
+	l.DebugFunc(fn)
+	l.TraceFunc(fn)
+	if calls != 0 {
+		t.Fatalf("fn called %d times for disabled levels", calls)
+	}
+
+	l.WarnFunc(fn)
+	if calls != 1 || !strings.Contains(buf.String(), "[W]built\n") {
+		t.Fatalf("calls = %d, output = %q", calls, buf.String())
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf), OptionLevel(LevelInfo))
+
+	called := false
+	l.DebugLazy(func() string {
+		called = true
+		return "dump"
+	})
+	if called {
+		t.Fatal("fn called while debug is off")
+	}
+
+	l.WithLevel(LevelDebug).DebugLazy(func() string { return "dump" })
+	if !strings.Contains(buf.String(), "[D]dump\n") {
+		t.Fatalf("output = %q", buf.String())
+	}
+}
@@ -0,0 +1,49 @@
+package llog
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []func(context.Context) Fields
+)
+
+// RegisterContextExtractor registers fn to pull request-scoped fields,
+// such as a request or trace ID, out of a context for WithContext.
+// It is usually called from init.
+func RegisterContextExtractor(fn func(context.Context) Fields) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	extractors = append(extractors, fn)
+}
+
+// ContextFields runs the registered extractors on ctx in registration
+// order and merges their results. When several return the same key, the
+// one registered last wins.
+func ContextFields(ctx context.Context) Fields {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	var fields Fields
+	for _, fn := range extractors {
+		for k, v := range fn(ctx) {
+			if fields == nil {
+				fields = make(Fields)
+			}
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// WithContext returns a logger carrying the fields extracted from ctx
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	return l.WithFields(ContextFields(ctx))
+}
@@ -0,0 +1,41 @@
+package llog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// CounterHook is a Hook counting lines per level, for exporting to a
+// metrics system such as Prometheus from a collector reading Counts.
+type CounterHook struct {
+	counts sync.Map // Level -> *atomic.Uint64
+}
+
+// NewCounterHook returns a CounterHook reacting to every level, including
+// the ones registered with RegisterLevel before the hook is added
+func NewCounterHook() *CounterHook {
+	return &CounterHook{}
+}
+
+func (h *CounterHook) Levels() []Level {
+	return registeredLevels()
+}
+
+func (h *CounterHook) Fire(level Level, _ string) error {
+	c, ok := h.counts.Load(level)
+	if !ok {
+		c, _ = h.counts.LoadOrStore(level, new(atomic.Uint64))
+	}
+	c.(*atomic.Uint64).Add(1)
+	return nil
+}
+
+// Counts returns a snapshot of the number of lines seen per level
+func (h *CounterHook) Counts() map[Level]uint64 {
+	counts := make(map[Level]uint64)
+	h.counts.Range(func(k, v any) bool {
+		counts[k.(Level)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return counts
+}
@@ -0,0 +1,33 @@
+package llog_test
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/nayotta/llog"
+)
+
+func ExampleCounterHook() {
+	counter := llog.NewCounterHook()
+	l := llog.New(llog.OptionOutput(io.Discard)).WithHook(counter)
+
+	l.Error("connection lost")
+	l.Warn("retrying")
+	l.Warn("retrying")
+	l.Debug("below the level, not counted")
+
+	// a metrics collector would export these, labelled by level
+	counts := counter.Counts()
+	levels := make([]llog.Level, 0, len(counts))
+	for level := range counts {
+		levels = append(levels, level)
+	}
+	slices.Sort(levels)
+	for _, level := range levels {
+		fmt.Println(level, counts[level])
+	}
+	// Output:
+	// [E] 1
+	// [W] 2
+}
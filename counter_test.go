@@ -0,0 +1,36 @@
+package llog
+
+import (
+	"sync"
+	"testing"
+)
+
+// levelNotice is a custom level registered once for the whole test binary,
+// since RegisterLevel panics on a second registration under -count
+const levelNotice Level = -10
+
+var registerNotice sync.Once
+
+func TestCounterHook(t *testing.T) {
+	registerNotice.Do(func() { RegisterLevel(levelNotice, "NOTICE") })
+
+	counter := NewCounterHook()
+	l := New(OptionOutput(nopWriter{}), OptionLevel(LevelDebug)).WithHook(counter)
+	l.Error("a")
+	l.Error("b")
+	l.Info("c")
+	l.Debug("d")
+	l.Trace("below the level")
+	l.Log(levelNotice, "e")
+
+	want := map[Level]uint64{LevelError: 2, LevelInfo: 1, LevelDebug: 1, levelNotice: 1}
+	got := counter.Counts()
+	if len(got) != len(want) {
+		t.Fatalf("Counts() = %v, want %v", got, want)
+	}
+	for level, n := range want {
+		if got[level] != n {
+			t.Fatalf("Counts() = %v, want %v", got, want)
+		}
+	}
+}
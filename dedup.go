@@ -0,0 +1,110 @@
+package llog
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// deduper collapses consecutive identical lines
+type deduper struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	logger   *Logger // private clone printing the summary of the repeats
+	level    Level
+	msg      string
+	since    time.Time
+	repeated int
+	gen      uint64
+	timer    *time.Timer
+}
+
+// check reports whether e should be printed, printing the summary of a
+// finished run of repeats first
+func (d *deduper) check(l *Logger, e *entry) bool {
+	d.mu.Lock()
+	if d.msg == e.msg && d.level == e.level && e.time.Sub(d.since) < d.window {
+		if d.repeated == 0 {
+			// a clone, since the caller may Release l before the summary
+			d.logger = l.Clone()
+		}
+		d.repeated++
+		if d.timer == nil {
+			gen := d.gen
+			d.timer = time.AfterFunc(d.since.Add(d.window).Sub(e.time), func() {
+				d.expire(gen)
+			})
+		}
+		d.mu.Unlock()
+		return false
+	}
+
+	last, level, n := d.logger, d.level, d.repeated
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.logger = nil
+	d.level = e.level
+	d.msg = e.msg
+	d.since = e.time
+	d.repeated = 0
+	d.gen++
+	d.mu.Unlock()
+
+	if n > 0 {
+		last.emitRepeated(level, n)
+	}
+	return true
+}
+
+// expire prints the summary once the window of run gen has elapsed
+func (d *deduper) expire(gen uint64) {
+	d.mu.Lock()
+	if d.gen != gen || d.repeated == 0 {
+		d.mu.Unlock()
+		return
+	}
+
+	last, level, n := d.logger, d.level, d.repeated
+	d.logger = nil
+	d.timer = nil
+	d.msg = ""
+	d.repeated = 0
+	d.gen++
+	d.mu.Unlock()
+
+	last.emitRepeated(level, n)
+}
+
+// emitRepeated prints the summary of n repeats, then releases l, the
+// clone taken by check
+func (l *Logger) emitRepeated(level Level, n int) {
+	l.emit(&entry{
+		level: level,
+		msg:   "last message repeated " + strconv.Itoa(n) + " times",
+		time:  nowFunc(),
+	})
+	l.Release()
+}
+
+// WithDedup returns a logger that collapses identical lines, compared by
+// level and message, arriving within window of the first one. The
+// repeats are reported by a "last message repeated N times" line once a
+// different message arrives or the window elapses. The dedup state is
+// shared by all loggers derived from the returned one.
+func (l *Logger) WithDedup(window time.Duration) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.dedup = nil
+	if window > 0 {
+		clone.dedup = &deduper{
+			window: window,
+		}
+	}
+	return clone
+}
@@ -0,0 +1,51 @@
+// Package llog is a leveled logger with tags, structured fields and
+// pluggable outputs.
+//
+// # Text format
+//
+// The byte layout of a default text line is a stable contract that
+// downstream parsers may rely on. Changes to it are breaking changes.
+// A line is, in order:
+//
+//	[timestamp SP] level [ "[" tags "]" SP ] [file ":" line SP] [func SP] {key "=" value SP} prefix message suffix terminator [stack]
+//
+// The parts are:
+//   - timestamp: formatted with DefaultTimeFormat and followed by a
+//     single space. It is omitted entirely when the time format is empty.
+//   - level: a token such as [I], followed directly by the rest of the
+//     line, with no space.
+//   - tags: joined by the tag separator.
+//   - file: the full path, or its base name with WithShortCaller.
+//   - fields: printed in the order they were added. A value containing
+//     a space, '=', '"' or a control character such as a newline is
+//     quoted with strconv.Quote, so it can't pass for another field or
+//     another line.
+//   - message: printed as is. A message already ending with the
+//     terminator isn't terminated twice. A multi-line message is not
+//     indented, so its continuation lines have no header unless
+//     WithMultilinePrefix is set.
+//   - stack: when WithStackTrace applies, follows the terminator.
+//
+// With a fixed clock from SetClock, each call below prints the lines in
+// the comment after it:
+//
+//	l.Info("hello")
+//	// 2024/01/02 03:04:05.006 [I]hello
+//	l.WithTag("http").Info("hello")
+//	// 2024/01/02 03:04:05.006 [I][http] hello
+//	l.WithFileAndLine(true).WithShortCaller(true).Info("hello")
+//	// 2024/01/02 03:04:05.006 [I]main.go:15 hello
+//	l.WithTag("http").WithFileAndLine(true).WithShortCaller(true).WithFields(llog.Fields{"k": "v"}).Error("hello")
+//	// 2024/01/02 03:04:05.006 [E][http] main.go:16 k=v hello
+//	l.Info("")
+//	// 2024/01/02 03:04:05.006 [I]
+//	l.Info("hello\n")
+//	// 2024/01/02 03:04:05.006 [I]hello
+//	l.Info("a\nb")
+//	// 2024/01/02 03:04:05.006 [I]a
+//	// b
+//
+// Two options change this layout. WithFieldSeparator replaces the spaces
+// between tokens, and WithHeaderStyle drops the timestamp and level. The
+// JSON, OTel and logfmt formats have layouts of their own.
+package llog
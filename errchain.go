@@ -0,0 +1,51 @@
+package llog
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// CauseKey is the field key WithErrorChain stores the causes under
+const CauseKey = "cause"
+
+// maxErrorChain bounds the causes WithErrorChain collects, in case an
+// Unwrap method forms a cycle
+const maxErrorChain = 16
+
+// errorChain is the messages of an error's causes, outermost first. It
+// renders as a JSON array, and joined by " -> " in text.
+type errorChain []string
+
+func (c errorChain) String() string {
+	return strings.Join(c, " -> ")
+}
+
+func (c errorChain) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string(c))
+}
+
+// WithErrorChain returns a logger with err attached like WithError, plus
+// the messages of the errors it wraps, found with errors.Unwrap, as the
+// CauseKey field:
+//
+//	err := fmt.Errorf("load config: %w", fmt.Errorf("open: %w", fs.ErrNotExist))
+//	l.WithErrorChain(err).Error("startup failed")
+//	// error=load config: open: file does not exist cause="open: file does not exist -> file does not exist"
+//
+// At most 16 causes are listed. An error wrapping nothing gets no cause
+// field, and a nil err adds no field at all.
+func (l *Logger) WithErrorChain(err error) *Logger {
+	if err == nil {
+		return l.Clone()
+	}
+
+	var chain errorChain
+	for cause := errors.Unwrap(err); cause != nil && len(chain) < maxErrorChain; cause = errors.Unwrap(cause) {
+		chain = append(chain, cause.Error())
+	}
+	if len(chain) == 0 {
+		return l.WithError(err)
+	}
+	return l.With(Any(ErrorKey, err), Any(CauseKey, chain))
+}
@@ -0,0 +1,205 @@
+package llog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Fields is a set of structured key-value pairs attached to a logger
+type Fields map[string]any
+
+// sortedKeys returns the keys of f in ascending order
+func (f Fields) sortedKeys() []string {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type fieldKind uint8
+
+const (
+	fieldAny fieldKind = iota
+	fieldDuration
+	fieldTime
+)
+
+// Field is a typed key-value pair for With
+type Field struct {
+	Key    string
+	Value  any
+	kind   fieldKind
+	groups []string // set by Group, with Key prefixed by them
+}
+
+// String returns a string field
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns an int field
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns a bool field
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 returns a float64 field
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Any returns a field holding an arbitrary value, rendered like a
+// WithFields value
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration returns a field rendering d as fractional milliseconds, so it
+// stays numeric in JSON output
+func Duration(key string, d time.Duration) Field {
+	return Field{Key: key, Value: d, kind: fieldDuration}
+}
+
+// Time returns a field rendering t in the logger's time format
+func Time(key string, t time.Time) Field {
+	return Field{Key: key, Value: t, kind: fieldTime}
+}
+
+// addFields returns the fields of l followed by add, placed in the groups
+// of l. A key already present keeps its position and takes the new value.
+// The result is always a new slice: l.fields is shared with every logger
+// derived from l and must never be written to.
+func (l *Logger) addFields(add []Field) []Field {
+	return l.addFieldsIn(l.groups, l.groupPrefix, add)
+}
+
+// addFieldsIn is addFields placing add in groups, whose keys are joined
+// in prefix, instead of the groups of l
+func (l *Logger) addFieldsIn(groups []string, prefix string, add []Field) []Field {
+	out := make([]Field, len(l.fields), len(l.fields)+len(add))
+	copy(out, l.fields)
+next:
+	for _, f := range add {
+		if len(groups) > 0 {
+			f.groups = groups
+			f.Key = prefix + f.Key
+		}
+		for i := range out {
+			if out[i].Key == f.Key {
+				out[i] = f
+				continue next
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// toFields converts m to fields in key order
+func (f Fields) toFields() []Field {
+	fields := make([]Field, 0, len(f))
+	for _, k := range f.sortedKeys() {
+		if tf, ok := f[k].(Field); ok {
+			tf.Key = k
+			fields = append(fields, tf)
+			continue
+		}
+		fields = append(fields, Field{Key: k, Value: f[k]})
+	}
+	return fields
+}
+
+// fieldValue resolves f to the value to render
+func (l *Logger) fieldValue(f Field) any {
+	switch f.kind {
+	case fieldDuration:
+		return float64(f.Value.(time.Duration)) / float64(time.Millisecond)
+	case fieldTime:
+		t := f.Value.(time.Time)
+		if l.utc {
+			t = t.UTC()
+		}
+		switch l.timeFormat {
+		case "":
+			return t.Format(time.RFC3339Nano)
+		case TimeFormatUnixMillis:
+			return t.UnixMilli()
+		}
+		return t.Format(l.timeFormat)
+	}
+	if s, ok := f.Value.(string); ok {
+		return l.redact(s)
+	}
+	return f.Value
+}
+
+// appendFieldValue appends v for text output, encoding common types
+// directly and others with fmt
+func appendFieldValue(buf *[]byte, v any) {
+	switch v := v.(type) {
+	case string:
+		appendTextString(buf, v)
+	case int:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int64:
+		*buf = strconv.AppendInt(*buf, v, 10)
+	case bool:
+		*buf = strconv.AppendBool(*buf, v)
+	case float64:
+		*buf = strconv.AppendFloat(*buf, v, 'g', -1, 64)
+	case error:
+		appendTextString(buf, errorString(v))
+	case errorChain:
+		appendTextString(buf, v.String())
+	default:
+		start := len(*buf)
+		*buf = fmt.Append(*buf, v)
+		if s := string((*buf)[start:]); textNeedsQuote(s) {
+			*buf = strconv.AppendQuote((*buf)[:start], s)
+		}
+	}
+}
+
+// textNeedsQuote reports whether a text field value holds a space, '=',
+// '"' or a control character, any of which would let it pass for more
+// key=value pairs or another line
+func textNeedsQuote(s string) bool {
+	return s != "" && logfmtNeedsQuote(s)
+}
+
+// appendTextString appends s, quoted with strconv.Quote if textNeedsQuote
+func appendTextString(buf *[]byte, s string) {
+	if textNeedsQuote(s) {
+		*buf = strconv.AppendQuote(*buf, s)
+		return
+	}
+	*buf = append(*buf, s...)
+}
+
+// errorString returns err.Error(), leaving a panicking Error method such
+// as one on a nil pointer to fmt's handling
+func errorString(err error) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = fmt.Sprint(err)
+		}
+	}()
+	return err.Error()
+}
+
+func (l *Logger) formatFields(buf *[]byte, sep string) {
+	for _, f := range l.fields {
+		*buf = append(*buf, f.Key...)
+		*buf = append(*buf, '=')
+		appendFieldValue(buf, l.fieldValue(f))
+		*buf = append(*buf, sep...)
+	}
+}
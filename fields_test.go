@@ -0,0 +1,67 @@
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFieldTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"text", FormatText, "[I]s=a n=-3 ok=true f=1.5 u=7 msg\n"},
+		{"logfmt", FormatLogfmt, "level=info msg=msg s=a n=-3 ok=true f=1.5 u=7\n"},
+		{"JSON", FormatJSON, `{"level":"info","msg":"msg","s":"a","n":-3,"ok":true,"f":1.5,"u":7}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithTimeFormat("").WithFormat(tt.format).
+				With(String("s", "a"), Int("n", -3), Bool("ok", true), Float64("f", 1.5), Any("u", uint(7))).
+				Info("msg")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkFields logs a line with ten typed fields in each format
+func BenchmarkFields(b *testing.B) {
+	fields := []Field{
+		String("method", "GET"), String("path", "/api/v1/users"), Int("status", 200),
+		Int("bytes", 5120), Bool("cached", false), Float64("ratio", 0.75),
+		Duration("took", 3*time.Millisecond), String("remote", "10.0.0.1"),
+		Int("attempt", 1), Any("user", 42),
+	}
+	formats := []struct {
+		name   string
+		format Format
+	}{
+		{"Text", FormatText},
+		{"Logfmt", FormatLogfmt},
+		{"JSON", FormatJSON},
+	}
+	for _, f := range formats {
+		b.Run(f.name, func(b *testing.B) {
+			l := New(OptionOutput(nopWriter{})).WithFormat(f.format).With(fields...)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Info("request handled")
+			}
+		})
+	}
+}
+
+func TestErrorFieldQuoted(t *testing.T) {
+	var buf bytes.Buffer
+	New(OptionOutput(&buf)).WithTimeFormat("").
+		WithError(errors.New("dial tcp 1.2.3.4: connection refused")).Error("failed")
+	if got, want := buf.String(), `[E]error="dial tcp 1.2.3.4: connection refused" failed`+"\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package llog
+
+import "fmt"
+
+// WithFilter returns a logger that suppresses lines for which fn returns
+// false. It sees the final message after level filtering and before
+// formatting; repeated calls add filters that must all pass:
+//
+//	l = l.WithFilter(func(level llog.Level, msg string) bool {
+//		return !strings.Contains(msg, "/healthz")
+//	})
+//
+// A filter that panics is reported to the error handler and the line is
+// kept.
+func (l *Logger) WithFilter(fn func(level Level, msg string) bool) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.filters = append(l.filters[:len(l.filters):len(l.filters)], fn)
+	return clone
+}
+
+// filter reports whether e passes every filter of l
+func (l *Logger) filter(e *entry) bool {
+	for _, fn := range l.filters {
+		if !l.runFilter(fn, e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *Logger) runFilter(fn func(Level, string) bool, e *entry) (keep bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.handleError(fmt.Errorf("llog: filter panicked: %v", r))
+			keep = true
+		}
+	}()
+	return fn(e.level, e.msg)
+}
@@ -0,0 +1,38 @@
+package llog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWithFilter(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).WithTimeFormat("").
+		WithFilter(func(_ Level, msg string) bool { return !strings.Contains(msg, "/healthz") }).
+		WithFilter(func(level Level, _ string) bool { return level != LevelDebug })
+	l = l.WithLevel(LevelDebug)
+
+	l.Info("GET /healthz")
+	l.Debug("GET /users")
+	l.Info("GET /users")
+	if got, want := buf.String(), "[I]GET /users\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestWithFilterPanic(t *testing.T) {
+	var buf bytes.Buffer
+	var errs []error
+	l := New(OptionOutput(&buf)).WithTimeFormat("").
+		WithErrorHandler(func(err error) { errs = append(errs, err) }).
+		WithFilter(func(Level, string) bool { panic("broken filter") })
+
+	l.Info("kept")
+	if got, want := buf.String(), "[I]kept\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken filter") {
+		t.Fatalf("errors = %v", errs)
+	}
+}
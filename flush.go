@@ -0,0 +1,150 @@
+package llog
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+type flusher interface {
+	Flush() error
+}
+
+type syncer interface {
+	Sync() error
+}
+
+// flushWriter flushes w if it supports Flush, or Sync as *os.File does
+func flushWriter(w io.Writer) error {
+	switch w := w.(type) {
+	case flusher:
+		return w.Flush()
+	case syncer:
+		return syncWriter(w)
+	}
+	return nil
+}
+
+// syncWriter syncs s, ignoring the error of files that can't be synced,
+// such as stderr when it is a pipe, a terminal or /dev/null
+func syncWriter(s syncer) error {
+	err := s.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, errors.ErrUnsupported) {
+		return nil
+	}
+	return err
+}
+
+// closeWriter closes w if it is an io.Closer, except for the standard
+// streams, which outlive every logger
+func closeWriter(w io.Writer) error {
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (w *mutexWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return flushWriter(w.Writer)
+}
+
+func (w *mutexWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if s, ok := w.Writer.(syncer); ok {
+		return syncWriter(s)
+	}
+	return nil
+}
+
+func (w *mutexWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return closeWriter(w.Writer)
+}
+
+// writers returns all distinct destinations of l
+func (l *Logger) writers() []*mutexWriter {
+	ws := []*mutexWriter{l.out}
+outer:
+	for _, w := range l.levelOut {
+		for _, seen := range ws {
+			if w == seen {
+				continue outer
+			}
+		}
+		ws = append(ws, w)
+	}
+	for _, t := range l.targets {
+		ws = append(ws, t.out)
+	}
+	return ws
+}
+
+// Flush flushes every output of l that implements Flush() error, or
+// Sync() error as *os.File does, so buffered lines are durable:
+//
+//	defer llog.Default().Flush()
+//
+// Writers supporting neither are skipped, as are files that can't be
+// synced, such as a terminal or pipe.
+func (l *Logger) Flush() error {
+	if l == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, w := range l.writers() {
+		if err := w.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync fsyncs every output of l that implements Sync() error, such as an
+// *os.File, so written lines survive a crash:
+//
+//	defer llog.Default().Sync()
+//
+// Unlike Flush, it doesn't push out lines held in user-space buffers such
+// as NewBufferedWriter's; call Flush first for those. Writers without Sync
+// are skipped, and so is the error of files that can't be synced.
+func (l *Logger) Sync() error {
+	if l == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, w := range l.writers() {
+		if err := w.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every output of l that implements io.Closer, except
+// os.Stdout and os.Stderr. The logger must not be used afterwards.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, w := range l.writers() {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
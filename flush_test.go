@@ -0,0 +1,106 @@
+package llog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFlushBuffered(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(NewBufferedWriter(&buf, time.Hour, 1<<20)))
+
+	l.Info("held")
+	if buf.Len() != 0 {
+		t.Fatalf("written before Flush: %q", buf.String())
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("held")) {
+		t.Fatalf("output = %q", buf.String())
+	}
+}
+
+func TestFlushUnsyncableFile(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	l := New(OptionOutput(w))
+	if err := l.Flush(); err != nil {
+		t.Fatalf("Flush on a pipe = %v", err)
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatalf("Sync on a pipe = %v", err)
+	}
+	if err := New(OptionOutput(os.Stderr)).Flush(); err != nil {
+		t.Fatalf("Flush on stderr = %v", err)
+	}
+}
+
+func TestSyncFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	l := New(OptionOutput(f))
+	l.Info("durable")
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCloseKeepsStderr(t *testing.T) {
+	if err := New(OptionOutput(os.Stderr)).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stderr.Stat(); err != nil {
+		t.Fatalf("stderr closed: %v", err)
+	}
+}
+
+func TestFatalFlushes(t *testing.T) {
+	var code int
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+
+	var buf bytes.Buffer
+	l := New(OptionOutput(NewBufferedWriter(&buf, time.Hour, 1<<20)))
+	l.Info("before")
+	l.Fatalf("giving up after %d tries", 3)
+
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	for _, want := range []string{"before", "giving up after 3 tries"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Fatalf("output = %q, missing %q", buf.String(), want)
+		}
+	}
+}
+
+func TestFatalFlushesWhenDisabled(t *testing.T) {
+	var code int
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+
+	var buf bytes.Buffer
+	l := New(OptionOutput(NewBufferedWriter(&buf, time.Hour, 1<<20)))
+	l.Info("held")
+	l.WithLevel(LevelOff).Fatal("hidden")
+
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if got := buf.String(); got == "" || bytes.Contains(buf.Bytes(), []byte("hidden")) {
+		t.Fatalf("output = %q", got)
+	}
+}
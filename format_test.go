@@ -0,0 +1,147 @@
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name.golden, or rewrites the file
+// when the tests run with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("output = %q, want %q from %s", got, want, path)
+	}
+}
+
+// TestTextFormat pins the text line layout documented in the package doc.
+// Reported callers are written as format_test.go:LINE in the golden
+// files, so they don't change whenever this file does.
+func TestTextFormat(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(func() time.Time { return fixedTime })
+
+	tests := []struct {
+		name string
+		log  func(l *Logger) int // returns the line of the call, 0 without caller
+	}{
+		{"plain", func(l *Logger) int {
+			l.Info("hello")
+			return 0
+		}},
+		{"tag", func(l *Logger) int {
+			l.WithTag("http").Info("hello")
+			return 0
+		}},
+		{"file_and_line", func(l *Logger) int {
+			l.WithFileAndLine(true).WithShortCaller(true).Info("hello")
+			return line() - 1
+		}},
+		{"combined", func(l *Logger) int {
+			l.WithTag("http").WithFileAndLine(true).WithShortCaller(true).WithFields(Fields{"k": "v"}).Error("hello")
+			return line() - 1
+		}},
+		{"quoted_values", func(l *Logger) int {
+			l.With(String("q", `say "hi"`), String("eq", "a=b"), String("nl", "x\n[E]forged"), String("ok", "plain")).
+				WithError(errors.New("dial tcp 1.2.3.4: connection refused")).Error("hello")
+			return 0
+		}},
+		{"empty", func(l *Logger) int {
+			l.Info("")
+			return 0
+		}},
+		{"trailing_newline", func(l *Logger) int {
+			l.Info("hello\n")
+			return 0
+		}},
+		{"multi-line", func(l *Logger) int {
+			l.Info("a\nb")
+			return 0
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n := tt.log(New(OptionOutput(&buf)).WithUTC(true))
+			got := buf.Bytes()
+			if n != 0 {
+				got = bytes.Replace(got, fmt.Appendf(nil, "format_test.go:%d ", n), []byte("format_test.go:LINE "), 1)
+			}
+			checkGolden(t, "text/"+tt.name, got)
+		})
+	}
+}
+
+// line returns the line number of its call site
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+func TestWithMultilinePrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{"three lines", "a\nb\nc", "[I][db] <a>\n[I][db] <b>\n[I][db] <c>\n"},
+		{"trailing newline", "a\nb\n", "[I][db] <a>\n[I][db] <b>\n"},
+		{"single line", "a", "[I][db] <a>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithTimeFormat("").WithTag("db").
+				WithPrefix("<").WithSuffix(">").WithMultilinePrefix(true).Info(tt.msg)
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPrefixSuffix(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{"plain", "x", "[I][api] svc[42] x>\n"},
+		{"trailing newline", "x\n", "[I][api] svc[42] x>\n"},
+		{"empty", "", "[I][api] svc[42] >\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithTimeFormat("").WithTag("api").
+				WithPrefix("svc[42] ").WithSuffix(">").Info(tt.msg)
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
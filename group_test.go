@@ -0,0 +1,59 @@
+package llog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"text", FormatText, "[I]app=api http.req.method=GET http.req.status=200 done\n"},
+		{"JSON", FormatJSON, `{"level":"info","msg":"done","app":"api","http":{"req":{"method":"GET","status":200}}}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(OptionOutput(&buf)).WithTimeFormat("").WithFormat(tt.format).
+				With(String("app", "api"))
+			l.Group("http").Group("req").With(String("method", "GET")).
+				With(Int("status", 200)).Info("done")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupEmptyName(t *testing.T) {
+	var buf bytes.Buffer
+	New(OptionOutput(&buf)).WithTimeFormat("").Group("").With(String("k", "v")).Info("flat")
+	if got, want := buf.String(), "[I]k=v flat\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestWithCodeInGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"text", FormatText, "[E]http.method=GET code=E_TIMEOUT http.status=504 upstream failed\n"},
+		{"JSON", FormatJSON, `{"level":"error","msg":"upstream failed","http":{"method":"GET","status":504},"code":"E_TIMEOUT"}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithTimeFormat("").WithFormat(tt.format).
+				Group("http").With(String("method", "GET")).
+				WithCode("E_TIMEOUT").With(Int("status", 504)).Error("upstream failed")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
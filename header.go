@@ -0,0 +1,41 @@
+package llog
+
+// HeaderStyle is a preset for the timestamp and level of text headers
+type HeaderStyle int
+
+// Header styles
+const (
+	HeaderFull      HeaderStyle = iota // DefaultTimeFormat and level, the default
+	HeaderTimeOnly                     // time of day as 15:04:05.000 and level
+	HeaderLevelOnly                    // level only, as WithTimeFormat("")
+	HeaderNone                         // neither timestamp nor level
+)
+
+// WithHeaderStyle returns a logger using the header preset style, for
+// environments such as containers where lines are timestamped already:
+//
+//	l.WithHeaderStyle(llog.HeaderLevelOnly).Info("ready") // [I]ready
+//
+// A style sets the time format as WithTimeFormat would, so a later
+// WithTimeFormat overrides it. Tags, caller and fields are unaffected. The
+// level is only ever hidden in text output; JSON lines keep it.
+func (l *Logger) WithHeaderStyle(style HeaderStyle) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.hideLevel = false
+	switch style {
+	case HeaderTimeOnly:
+		clone.timeFormat = "15:04:05.000"
+	case HeaderLevelOnly:
+		clone.timeFormat = ""
+	case HeaderNone:
+		clone.timeFormat = ""
+		clone.hideLevel = true
+	default:
+		clone.timeFormat = DefaultTimeFormat
+	}
+	return clone
+}
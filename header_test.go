@@ -0,0 +1,51 @@
+package llog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWithHeaderStyle(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(func() time.Time { return fixedTime })
+
+	tests := []struct {
+		name  string
+		style HeaderStyle
+		want  string
+	}{
+		{"full", HeaderFull, "2024/01/02 03:04:05.006 [I][api] ready\n"},
+		{"time only", HeaderTimeOnly, "03:04:05.006 [I][api] ready\n"},
+		{"level only", HeaderLevelOnly, "[I][api] ready\n"},
+		{"none", HeaderNone, "[api] ready\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithUTC(true).WithTag("api").WithHeaderStyle(tt.style).Info("ready")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderNoneKeepsJSONLevel(t *testing.T) {
+	var buf bytes.Buffer
+	New(OptionOutput(&buf)).WithHeaderStyle(HeaderNone).WithFormat(FormatJSON).Info("ready")
+	if got, want := buf.String(), `{"level":"info","msg":"ready"}`+"\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestHeaderStyleThenTimeFormat(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(func() time.Time { return fixedTime })
+
+	var buf bytes.Buffer
+	New(OptionOutput(&buf)).WithUTC(true).WithHeaderStyle(HeaderLevelOnly).WithTimeFormat(TimeFormatRFC3339).Info("ready")
+	if got, want := buf.String(), "2024-01-02T03:04:05Z [I]ready\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package llog
+
+// Hook is notified of every line logged at one of its levels
+type Hook interface {
+	Levels() []Level
+	Fire(level Level, msg string) error
+}
+
+// hooks is an immutable set of hooks by level. Adding a hook builds a new
+// set, so a hook added to one logger never reaches its parent or siblings.
+type hooks struct {
+	byLevel map[Level][]Hook
+	count   int
+}
+
+// with returns h plus hook
+func (h *hooks) with(hook Hook) *hooks {
+	n := &hooks{
+		byLevel: make(map[Level][]Hook),
+		count:   1,
+	}
+	if h != nil {
+		for level, hs := range h.byLevel {
+			n.byLevel[level] = hs
+		}
+		n.count += h.count
+	}
+	for _, level := range hook.Levels() {
+		hs := n.byLevel[level]
+		n.byLevel[level] = append(hs[:len(hs):len(hs)], hook)
+	}
+	return n
+}
+
+func (h *hooks) len() int {
+	if h == nil {
+		return 0
+	}
+	return h.count
+}
+
+func (h *hooks) fire(l *Logger, level Level, msg string) {
+	if h == nil {
+		return
+	}
+
+	for _, hook := range h.byLevel[level] {
+		if err := hook.Fire(level, msg); err != nil {
+			l.handleError(err)
+		}
+	}
+}
+
+// WithHook returns a logger that fires hook after each line at one of its
+// levels is written, in addition to the hooks of l. Errors returned by
+// Fire go to the error handler. The hook is inherited by loggers derived
+// from the returned one, but l and its other descendants don't see it.
+//
+// Hooks run synchronously on the logging goroutine, so a slow hook slows
+// down logging; hand expensive work such as webhooks off to a goroutine.
+func (l *Logger) WithHook(hook Hook) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.hooks.Store(l.hooks.Load().with(hook))
+	return clone
+}
+
+// AddHook adds hook to l in place, like WithHook without deriving a new
+// logger. It is safe to call while other goroutines log through l. Loggers
+// derived from l afterwards inherit the hook; ones derived before don't.
+func (l *Logger) AddHook(hook Hook) {
+	if l == nil {
+		return
+	}
+
+	for {
+		old := l.hooks.Load()
+		if l.hooks.CompareAndSwap(old, old.with(hook)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package llog
+
+import (
+	"errors"
+	"testing"
+)
+
+// recordHook records the messages fired at its levels
+type recordHook struct {
+	levels []Level
+	msgs   []string
+	err    error
+}
+
+func (h *recordHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *recordHook) Fire(_ Level, msg string) error {
+	h.msgs = append(h.msgs, msg)
+	return h.err
+}
+
+func TestAddHookScope(t *testing.T) {
+	parent := New(OptionOutput(nopWriter{}))
+	sibling := parent.WithTag("http")
+	child := parent.WithTag("db")
+
+	h := &recordHook{levels: []Level{LevelInfo}}
+	child.AddHook(h)
+	parent.Info("parent")
+	sibling.Info("sibling")
+	child.Info("child")
+	child.WithTag("query").Info("grandchild")
+	child.Warn("other level")
+
+	if len(h.msgs) != 2 || h.msgs[0] != "child" || h.msgs[1] != "grandchild" {
+		t.Fatalf("fired for %q", h.msgs)
+	}
+}
+
+func TestWithHook(t *testing.T) {
+	base := New(OptionOutput(nopWriter{}))
+	first := &recordHook{levels: []Level{LevelError}}
+	second := &recordHook{levels: []Level{LevelError}}
+
+	l := base.WithHook(first)
+	ll := l.WithHook(second)
+	base.Error("base")
+	l.Error("one")
+	ll.Error("both")
+
+	if len(first.msgs) != 2 || len(second.msgs) != 1 || second.msgs[0] != "both" {
+		t.Fatalf("first fired for %q, second for %q", first.msgs, second.msgs)
+	}
+}
+
+func TestHookError(t *testing.T) {
+	errFire := errors.New("fire failed")
+	var got error
+	l := New(OptionOutput(nopWriter{})).
+		WithErrorHandler(func(err error) { got = err }).
+		WithHook(&recordHook{levels: []Level{LevelInfo}, err: errFire})
+
+	l.Info("x")
+	if got != errFire {
+		t.Fatalf("error handler got %v", got)
+	}
+}
+
+func TestHookOnDiscard(t *testing.T) {
+	h := &recordHook{levels: []Level{LevelInfo}}
+	l := NewDiscard().WithLevel(LevelInfo).WithHook(h)
+	l.Info("seen by the hook")
+	if len(h.msgs) != 1 {
+		t.Fatalf("fired for %q", h.msgs)
+	}
+}
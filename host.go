@@ -0,0 +1,34 @@
+package llog
+
+import (
+	"os"
+	"sync"
+)
+
+// hostname is looked up once, falling back to "unknown" on failure
+var hostname = sync.OnceValue(func() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "unknown"
+	}
+	return name
+})
+
+// HostKey and PIDKey are the field keys of WithHostname and WithPID
+const (
+	HostKey = "host"
+	PIDKey  = "pid"
+)
+
+// WithHostname returns a logger with the machine's hostname attached as
+// the HostKey field. The name is looked up once per process and is
+// "unknown" when the lookup fails.
+func (l *Logger) WithHostname() *Logger {
+	return l.With(String(HostKey, hostname()))
+}
+
+// WithPID returns a logger with the process ID attached as the PIDKey
+// field
+func (l *Logger) WithPID() *Logger {
+	return l.With(Int(PIDKey, os.Getpid()))
+}
@@ -0,0 +1,236 @@
+package llog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+const hex = "0123456789abcdef"
+
+// formatJSON encodes e as a single JSON object followed by the terminator
+func (l *Logger) formatJSON(buf *[]byte, e *entry) {
+	*buf = append(*buf, '{')
+	if l.timeFormat != "" {
+		*buf = append(*buf, `"ts":`...)
+		if l.timeFormat == TimeFormatUnixMillis {
+			*buf = appendTime(*buf, e.time, l.timeFormat)
+		} else {
+			appendJSONString(buf, e.time.Format(l.timeFormat))
+		}
+		*buf = append(*buf, ',')
+	}
+
+	*buf = append(*buf, `"level":`...)
+	appendJSONString(buf, e.level.name())
+
+	if l.tag != "" {
+		*buf = append(*buf, `,"tag":`...)
+		appendJSONString(buf, l.tag)
+	}
+
+	if l.fileAndLine {
+		*buf = append(*buf, `,"caller":`...)
+		appendJSONString(buf, e.file+":"+strconv.Itoa(e.line))
+	}
+
+	if l.callerFunc {
+		*buf = append(*buf, `,"func":`...)
+		appendJSONString(buf, e.fn)
+	}
+
+	*buf = append(*buf, `,"msg":`...)
+	appendJSONString(buf, e.msg)
+
+	l.appendJSONFields(buf, l.fields, 0, true)
+
+	if e.stack != "" {
+		*buf = append(*buf, `,"stack":`...)
+		appendJSONString(buf, e.stack)
+	}
+
+	*buf = append(*buf, '}')
+	*buf = append(*buf, l.terminator...)
+}
+
+// appendJSONFields encodes fields as members of an object, nesting those
+// in a group deeper than depth in an object per group, placed where the
+// group's first field was. comma tells whether members precede them.
+func (l *Logger) appendJSONFields(buf *[]byte, fields []Field, depth int, comma bool) {
+	var done []string
+	for i, f := range fields {
+		var g string
+		if len(f.groups) > depth {
+			g = f.groups[depth]
+			if slices.Contains(done, g) {
+				continue
+			}
+			done = append(done, g)
+		}
+
+		if comma {
+			*buf = append(*buf, ',')
+		}
+		comma = true
+
+		if g == "" {
+			appendJSONString(buf, f.Key[groupPrefixLen(f.groups):])
+			*buf = append(*buf, ':')
+			appendJSONValue(buf, l.fieldValue(f))
+			continue
+		}
+
+		var group []Field
+		for _, o := range fields[i:] {
+			if len(o.groups) > depth && o.groups[depth] == g {
+				group = append(group, o)
+			}
+		}
+		appendJSONString(buf, g)
+		*buf = append(*buf, ':', '{')
+		l.appendJSONFields(buf, group, depth+1, false)
+		*buf = append(*buf, '}')
+	}
+}
+
+// groupPrefixLen returns the length of the key prefix added for groups
+func groupPrefixLen(groups []string) int {
+	n := 0
+	for _, g := range groups {
+		n += len(g) + 1
+	}
+	return n
+}
+
+func appendJSONValue(buf *[]byte, v any) {
+	switch v := v.(type) {
+	case nil:
+		*buf = append(*buf, "null"...)
+	case string:
+		appendJSONString(buf, v)
+	case bool:
+		*buf = strconv.AppendBool(*buf, v)
+	case int:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int8:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int16:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int32:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int64:
+		*buf = strconv.AppendInt(*buf, v, 10)
+	case uint:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint8:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint16:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint32:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint64:
+		*buf = strconv.AppendUint(*buf, v, 10)
+	case float32:
+		appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		appendJSONFloat(buf, v, 64)
+	case json.Marshaler:
+		appendJSONMarshal(buf, v)
+	case error:
+		appendJSONString(buf, errorString(v))
+	case fmt.Stringer:
+		var s []byte
+		appendFieldValue(&s, v)
+		appendJSONString(buf, string(s))
+	default:
+		if isJSONComposite(v) {
+			appendJSONMarshal(buf, v)
+			return
+		}
+		var s []byte
+		appendFieldValue(&s, v)
+		appendJSONString(buf, string(s))
+	}
+}
+
+// isJSONComposite reports whether v is a struct, map, slice or array, or a
+// pointer to one, which encoding/json renders better than fmt
+func isJSONComposite(v any) bool {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// appendJSONMarshal embeds v encoded by encoding/json. A marshal failure
+// is rendered as a placeholder string so the rest of the line survives.
+func appendJSONMarshal(buf *[]byte, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		appendJSONString(buf, "!ERROR: "+err.Error())
+		return
+	}
+	*buf = append(*buf, b...)
+}
+
+func appendJSONFloat(buf *[]byte, f float64, bitSize int) {
+	// NaN and infinities are not representable in JSON
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bitSize))
+		return
+	}
+	*buf = strconv.AppendFloat(*buf, f, 'g', -1, bitSize)
+}
+
+// appendJSONString appends s as a quoted JSON string, escaping control
+// characters and replacing invalid UTF-8 with U+FFFD
+func appendJSONString(buf *[]byte, s string) {
+	*buf = append(*buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			*buf = append(*buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				*buf = append(*buf, '\\', b)
+			case '\n':
+				*buf = append(*buf, '\\', 'n')
+			case '\r':
+				*buf = append(*buf, '\\', 'r')
+			case '\t':
+				*buf = append(*buf, '\\', 't')
+			default:
+				*buf = append(*buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			*buf = append(*buf, s[start:i]...)
+			*buf = append(*buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	*buf = append(*buf, s[start:]...)
+	*buf = append(*buf, '"')
+}
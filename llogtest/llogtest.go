@@ -0,0 +1,87 @@
+// Package llogtest captures the output of an llog.Logger for assertions
+// in tests:
+//
+//	l, c := llogtest.New()
+//	doWork(l)
+//	if len(c.AtLevel(llog.LevelError)) == 0 || !c.Contains("timeout") {
+//		t.Error("expected a timeout error to be logged")
+//	}
+package llogtest
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/nayotta/llog"
+)
+
+// Capture records the lines written by a logger returned from New. It is
+// safe for concurrent use.
+type Capture struct {
+	mu     sync.Mutex
+	lines  []string
+	levels []llog.Level
+}
+
+// New returns a logger at LevelTrace without timestamps whose output is
+// recorded by the returned Capture
+func New() (*llog.Logger, *Capture) {
+	c := &Capture{}
+	l := llog.New(llog.OptionOutput(c), llog.OptionLevel(llog.LevelTrace)).WithTimeFormat("")
+	return l, c
+}
+
+func (c *Capture) Write(p []byte) (int, error) {
+	return c.WriteLevel(llog.LevelInfo, p)
+}
+
+func (c *Capture) WriteLevel(level llog.Level, p []byte) (int, error) {
+	c.mu.Lock()
+	c.lines = append(c.lines, strings.TrimSuffix(string(p), "\n"))
+	c.levels = append(c.levels, level)
+	c.mu.Unlock()
+	return len(p), nil
+}
+
+// Lines returns the captured lines without their terminator, oldest first
+func (c *Capture) Lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.lines...)
+}
+
+// AtLevel returns the captured lines logged at level
+func (c *Capture) AtLevel(level llog.Level) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var lines []string
+	for i, l := range c.levels {
+		if l == level {
+			lines = append(lines, c.lines[i])
+		}
+	}
+	return lines
+}
+
+// Contains reports whether any captured line contains substr
+func (c *Capture) Contains(substr string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, line := range c.lines {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset discards the captured lines
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	c.lines = nil
+	c.levels = nil
+	c.mu.Unlock()
+}
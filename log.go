@@ -3,27 +3,59 @@ package llog
 import (
 	"fmt"
 	"io"
+	"math"
+	"os"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 var bufPool sync.Pool
 
+// bufferSize is the initial capacity of pooled line buffers
+var bufferSize atomic.Int64
+
 func init() {
+	bufferSize.Store(1024)
 	bufPool = sync.Pool{
 		New: func() any {
-			buf := make([]byte, 0, 1024)
+			buf := make([]byte, 0, bufferSize.Load())
 			return &buf
 		},
 	}
 }
 
+// SetBufferSize sets the initial capacity in bytes of the buffers lines
+// are encoded into, 1024 by default. It is a tuning knob for throughput
+// sensitive deployments: raise it when lines are typically longer, so
+// encoding doesn't have to grow the buffer, or lower it for small lines to
+// save memory. Call it before logging; buffers already pooled keep their
+// size. Values below 1 are ignored.
+func SetBufferSize(n int) {
+	if n > 0 {
+		bufferSize.Store(int64(n))
+	}
+}
+
 type mutexWriter struct {
 	mu sync.Mutex
 	io.Writer
+
+	terminal bool
+	discard  bool // Writer is io.Discard
+}
+
+func newMutexWriter(w io.Writer) *mutexWriter {
+	return &mutexWriter{
+		Writer:   w,
+		terminal: isTerminal(w),
+		discard:  w == io.Discard,
+	}
 }
 
 func (w *mutexWriter) Write(b []byte) (int, error) {
@@ -33,171 +65,1314 @@ func (w *mutexWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+func (w *mutexWriter) WriteLevel(level Level, b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return writeLevel(w.Writer, level, b)
+}
+
 // Level defines what logs should be printed
 type Level int
 
-// LogLevels
+// LogLevels. LevelWarn, matching the Warn methods, is the canonical name
+// of the warning level; prefer it over LevelWarning in new code.
 const (
+	LevelOff     Level = math.MinInt32 // disables all output, even fatal
+	LevelFatal   Level = -3            // printed at every level but LevelOff
 	LevelError   Level = -2
-	LevelWarning Level = -1
-	LevelInfo    Level = 0 // default log level
+	LevelWarn    Level = -1
+	LevelWarning       = LevelWarn // original name of LevelWarn, kept for compatibility
+	LevelInfo    Level = 0         // default log level
 	LevelDebug   Level = 1
+	LevelTrace   Level = 2
 )
 
 var levelString = map[Level]string{
-	LevelError:   "[E]",
-	LevelWarning: "[W]",
-	LevelInfo:    "[I]",
-	LevelDebug:   "[D]",
+	LevelFatal: "[F]",
+	LevelError: "[E]",
+	LevelWarn:  "[W]",
+	LevelInfo:  "[I]",
+	LevelDebug: "[D]",
+	LevelTrace: "[T]",
+}
+
+var levelName = map[Level]string{
+	LevelFatal: "fatal",
+	LevelError: "error",
+	LevelWarn:  "warning",
+	LevelInfo:  "info",
+	LevelDebug: "debug",
+	LevelTrace: "trace",
 }
 
+// levelsMu guards the level maps against RegisterLevel
+var (
+	levelsMu     sync.RWMutex
+	customLevels = map[string]Level{}
+)
+
 func (level Level) String() string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
 	return levelString[level]
 }
 
-// Logger is a simple custom logger support log levels
+// name returns the lower-case name of level used by structured formats
+func (level Level) name() string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	return levelName[level]
+}
+
+// Format defines how a log line is encoded
+type Format int
+
+// Formats
+const (
+	FormatText Format = iota // default format
+	FormatJSON
+	FormatOTel   // JSON in the OpenTelemetry log data model
+	FormatLogfmt // key=value pairs
+)
+
+// DefaultTimeFormat is the timestamp layout used unless changed by WithTimeFormat
+const DefaultTimeFormat = "2006/01/02 15:04:05.000"
+
+// Time formats for WithTimeFormat
+const (
+	TimeFormatRFC3339     = time.RFC3339
+	TimeFormatRFC3339Nano = time.RFC3339Nano
+	TimeFormatISO8601     = "2006-01-02T15:04:05.000Z07:00"
+
+	// TimeFormatUnixMillis isn't a layout: it prints milliseconds since the
+	// Unix epoch as an integer, a number in JSON output
+	TimeFormatUnixMillis = "unixmillis"
+)
+
+// appendTime appends t formatted with layout, handling TimeFormatUnixMillis
+func appendTime(buf []byte, t time.Time, layout string) []byte {
+	if layout == TimeFormatUnixMillis {
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	}
+	return t.AppendFormat(buf, layout)
+}
+
+// Logger is a simple custom logger support log levels.
+// A nil *Logger is valid and logs nothing; its With methods return nil.
 type Logger struct {
-	out *mutexWriter
+	out      *mutexWriter
+	levelOut map[Level]*mutexWriter
+	targets  []target
+	noLock   bool // see WithUnsafeNoLock
+
+	level        atomic.Int64 // Level, changed in place by WithTemporaryLevel
+	tags         []string
+	tagSep       string
+	fieldSep     string // header token separator, "" for the classic layout
+	tag          string // tags joined by tagSep
+	tagHeader    []byte // tag as printed in text headers, see setTags
+	fileAndLine  bool
+	stackTrace   bool
+	stackLevel   Level
+	callerSkip   int
+	shortCaller  bool
+	callerTrim   string
+	callerFilter func(file string) bool
+	callerFunc   bool
+	timeFormat   string
+	hideLevel    bool // level token left out of text headers
+	utc          bool
+	maxMsgLen    int
+	multiline    bool
+	prefix       string
+	suffix       string
+	terminator   string
+
+	fields      []Field
+	groups      []string
+	groupPrefix string // groups joined by dots, with a trailing dot
 
-	level       Level
-	tag         string
-	fileAndLine bool
+	format Format
+	color  ColorMode
+
+	writerFunc func(level Level, msg string)
+	errHandler func(error)
+	filters    []func(level Level, msg string) bool
+	redactors  []func(string) string
+	sampler    *sampler
+	dedup      *deduper
+	hooks      atomic.Pointer[hooks] // swapped as a whole by AddHook
+	seen       *levelSeen
 }
 
 func (l *Logger) Level() Level {
-	return l.level
+	if l == nil {
+		return LevelOff
+	}
+	return Level(l.level.Load())
+}
+
+// Tag returns the tags of l joined by the tag separator
+func (l *Logger) Tag() string {
+	if l == nil {
+		return ""
+	}
+	return l.tag
+}
+
+// FileAndLine reports whether l includes the caller's file and line
+func (l *Logger) FileAndLine() bool {
+	if l == nil {
+		return false
+	}
+	return l.fileAndLine
+}
+
+// Output returns the writer l logs to, as passed to WithOutput, excluding
+// per-level outputs
+func (l *Logger) Output() io.Writer {
+	if l == nil {
+		return nil
+	}
+	return l.out.Writer
 }
 
 func (l *Logger) setLevel(level Level) {
-	l.level = level
+	l.level.Store(int64(level))
 }
 
-func (l *Logger) setLevelString(s string) {
+func parseLevel(s string) (Level, error) {
 	switch strings.ToLower(s) {
+	case "off":
+		return LevelOff, nil
+	case "fatal", "f":
+		return LevelFatal, nil
 	case "error", "e":
-		l.setLevel(LevelError)
-	case "warning", "w":
-		l.setLevel(LevelWarning)
+		return LevelError, nil
+	case "warning", "warn", "w":
+		return LevelWarn, nil
 	case "info", "i":
-		l.setLevel(LevelInfo)
+		return LevelInfo, nil
 	case "debug", "d":
-		l.setLevel(LevelDebug)
+		return LevelDebug, nil
+	case "trace", "t":
+		return LevelTrace, nil
 	}
+
+	levelsMu.RLock()
+	level, ok := customLevels[strings.ToLower(s)]
+	levelsMu.RUnlock()
+	if ok {
+		return level, nil
+	}
+	return 0, fmt.Errorf("llog: unknown level %q", s)
 }
 
-func (l *Logger) formatHeader(buf *[]byte, level Level) {
-	ts := time.Now().Format("2006/01/02 15:04:05.000 ")
-	*buf = append(*buf, ts...)
+// RegisterLevel adds a custom level printed as [label]. Its value decides
+// which loggers print it just like for the built-in levels: a logger
+// prints the levels at or below its own, so smaller values are more
+// severe. The built-in levels are adjacent, leaving no value between
+// them. A level below LevelFatal, such as -10 for AUDIT, is printed by
+// every logger but a LevelOff one; a level above LevelTrace, such as 3
+// for VERBOSE, only by loggers set to it or higher:
+//
+//	const LevelAudit llog.Level = -10
+//
+//	func init() { llog.RegisterLevel(LevelAudit, "AUDIT") }
+//
+// SetLevelString accepts label case-insensitively. RegisterLevel is meant
+// to be called from init and panics if level or label is already taken.
+func RegisterLevel(level Level, label string) {
+	name := strings.ToLower(label)
+	if name == "" {
+		panic("llog: empty level label")
+	}
+	if _, err := parseLevel(name); err == nil {
+		panic(fmt.Sprintf("llog: level label %q already registered", label))
+	}
 
-	ls := level.String()
-	*buf = append(*buf, ls...)
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
 
-	if l.tag != "" {
-		*buf = append(*buf, '[')
-		*buf = append(*buf, l.tag...)
-		*buf = append(*buf, ']', ' ')
+	if _, ok := levelString[level]; ok || level == LevelOff {
+		panic(fmt.Sprintf("llog: level %d already registered", level))
+	}
+	levelString[level] = "[" + label + "]"
+	levelName[level] = name
+	customLevels[name] = level
+}
+
+// registeredLevels returns the built-in and registered levels, most
+// severe first
+func registeredLevels() []Level {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	levels := make([]Level, 0, len(levelString))
+	for level := range levelString {
+		levels = append(levels, level)
+	}
+	slices.Sort(levels)
+	return levels
+}
+
+func (l *Logger) setLevelString(s string) error {
+	level, err := parseLevel(s)
+	if err != nil {
+		return err
+	}
+	l.setLevel(level)
+	return nil
+}
+
+// SetLevelString sets the level by name, case-insensitively. It accepts
+// "fatal", "error", "warn", "info", "debug", "trace" and their first
+// letters, "warning" and "off".
+func (l *Logger) SetLevelString(s string) error {
+	if l == nil {
+		return nil
+	}
+	return l.setLevelString(s)
+}
+
+// WithTemporaryLevel changes the level of l in place until the returned
+// restore func is called, for raising verbosity during one operation:
+//
+//	defer l.WithTemporaryLevel(llog.LevelDebug)()
+//
+// Loggers derived from l by With methods are unaffected, so this is
+// mostly useful on a logger shared by many goroutines; for std use the
+// package function of the same name. The change is visible to every
+// goroutine using l, and overlapping scopes restore in the order they
+// end, not the order they began. restore is idempotent and safe for
+// concurrent use.
+func (l *Logger) WithTemporaryLevel(level Level) (restore func()) {
+	if l == nil {
+		return func() {}
+	}
+
+	prev := Level(l.level.Swap(int64(level)))
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.setLevel(prev)
+		})
+	}
+}
+
+// entry holds everything resolved for a single log line
+type entry struct {
+	level Level
+	msg   string
+	time  time.Time
+	file  string
+	line  int
+	fn    string
+	pc    uintptr
+	stack string
+	color bool
+}
+
+// headerSep returns the separator between text header tokens
+func (l *Logger) headerSep() string {
+	if l.fieldSep == "" {
+		return " "
+	}
+	return l.fieldSep
+}
+
+func (l *Logger) formatHeader(buf *[]byte, e *entry) {
+	sep := l.headerSep()
+	if l.timeFormat != "" {
+		*buf = appendTime(*buf, e.time, l.timeFormat)
+		*buf = append(*buf, sep...)
 	}
 
+	if !l.hideLevel {
+		ls := e.level.String()
+		if e.color {
+			*buf = append(*buf, levelColor(e.level)...)
+			*buf = append(*buf, ls...)
+			*buf = append(*buf, colorReset...)
+		} else {
+			*buf = append(*buf, ls...)
+		}
+		if l.fieldSep != "" {
+			*buf = append(*buf, sep...)
+		}
+	}
+
+	*buf = append(*buf, l.tagHeader...)
+
 	if l.fileAndLine {
-		var ok bool
-		_, file, line, ok := runtime.Caller(3)
+		*buf = append(*buf, e.file...)
+		*buf = append(*buf, ':')
+		*buf = strconv.AppendInt(*buf, int64(e.line), 10)
+		*buf = append(*buf, sep...)
+	}
+
+	if l.callerFunc {
+		*buf = append(*buf, e.fn...)
+		*buf = append(*buf, sep...)
+	}
+
+	l.formatFields(buf, sep)
+}
+
+func (l *Logger) formatText(buf *[]byte, e *entry) {
+	// a message already ending with the terminator isn't terminated twice
+	msg := strings.TrimSuffix(e.msg, l.terminator)
+	for l.multiline {
+		line, rest, ok := strings.Cut(msg, "\n")
 		if !ok {
-			file = "???"
-			line = 0
+			break
 		}
+		l.formatLine(buf, e, line)
+		msg = rest
+	}
+	l.formatLine(buf, e, msg)
+	*buf = append(*buf, e.stack...)
+}
 
-		*buf = append(*buf, file...)
-		*buf = append(*buf, ':')
-		nu := strconv.Itoa(line)
-		*buf = append(*buf, nu...)
-		*buf = append(*buf, ' ')
+// formatLine appends one header, message line and terminator
+func (l *Logger) formatLine(buf *[]byte, e *entry, msg string) {
+	l.formatHeader(buf, e)
+	*buf = append(*buf, l.prefix...)
+	*buf = append(*buf, msg...)
+	*buf = append(*buf, l.suffix...)
+	*buf = append(*buf, l.terminator...)
+}
+
+// shortFile returns the base name of file, like log.Lshortfile
+func shortFile(file string) string {
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		return file[i+1:]
 	}
+	return file
+}
+
+// resolveCaller fills in the call site from e.pc. A single cached frame
+// lookup yields file, line and function together.
+func (l *Logger) resolveCaller(e *entry) {
+	if e.pc == 0 {
+		e.file = "???"
+		e.line = 0
+		return
+	}
+
+	frame := lookupCaller(e.pc)
+	e.file = frame.file
+	e.line = frame.line
+	e.fn = frame.fn
+	if e.file == "" {
+		e.file = "???"
+	} else if l.shortCaller {
+		e.file = shortFile(e.file)
+	} else if l.callerTrim != "" {
+		e.file = strings.TrimPrefix(e.file, l.callerTrim)
+	}
+}
+
+// enabled reports whether a line at level should be formatted and logged.
+// It is called by the entry points only, so a line skipped because it
+// would reach io.Discard still counts for HighestLevelSeen.
+func (l *Logger) enabled(level Level) bool {
+	if !l.levelEnabled(level) {
+		return false
+	}
+	if l.discards(level) {
+		l.seen.observe(level)
+		return false
+	}
+	return true
+}
+
+// levelEnabled reports whether level passes the level of l
+func (l *Logger) levelEnabled(level Level) bool {
+	if l == nil {
+		return false
+	}
+
+	current := l.Level()
+	if current == LevelOff {
+		return false
+	}
+	return level == LevelFatal || level <= current
+}
+
+// discards reports whether lines at level would only reach io.Discard,
+// so the work of formatting them can be skipped entirely
+func (l *Logger) discards(level Level) bool {
+	return l.writerFunc == nil && len(l.targets) == 0 &&
+		l.writer(level).discard && l.hooks.Load().len() == 0
+}
+
+// Enabled reports whether a line at level would be printed, so expensive
+// messages can be skipped. It is false when the line would only reach
+// io.Discard, as with WithOutput(io.Discard) and no hooks:
+//
+//	if l.Enabled(llog.LevelDebug) {
+//		l.Debug(expensiveDump())
+//	}
+func (l *Logger) Enabled(level Level) bool {
+	return l.levelEnabled(level) && !l.discards(level)
+}
+
+// callerDepth is the number of frames between output and the user's call
+// site: output itself and the exported entry point. Every entry point must
+// call output or outputFunc directly, never through another llog method,
+// or the reported caller will be off by a frame.
+const callerDepth = 2
+
+// callerPC returns the pc of the frame skip levels above its caller
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(skip+2, pcs[:])
+	return pcs[0]
 }
 
 func (l *Logger) output(level Level, s string) {
-	if level > l.level {
+	if !l.enabled(level) {
+		return
+	}
+	l.outputPC(level, s, l.caller())
+}
+
+// outputFunc is output with the message built by fn, which is only called
+// once the level check passes. The Func and Lazy variants share it.
+func (l *Logger) outputFunc(level Level, fn func() string) {
+	if !l.enabled(level) {
+		return
+	}
+	l.outputPC(level, fn(), l.caller())
+}
+
+// caller returns the pc of the call site of the entry point that called
+// output or outputFunc, or 0 when the line doesn't include the caller
+func (l *Logger) caller() uintptr {
+	if !l.fileAndLine && !l.callerFunc {
+		return 0
+	}
+
+	// one more frame for caller itself
+	skip := callerDepth + 1 + l.callerSkip
+	if l.callerFilter != nil {
+		return l.filteredCallerPC(skip)
+	}
+	return callerPC(skip)
+}
+
+// outputPC logs s with an already resolved caller pc
+func (l *Logger) outputPC(level Level, s string, pc uintptr) {
+	e := entry{
+		level: level,
+		msg:   s,
+		time:  nowFunc(),
+		pc:    pc,
+	}
+	l.process(&e)
+}
+
+// process applies the per-line policies to e, then emits it
+func (l *Logger) process(e *entry) {
+	l.seen.observe(e.level)
+	e.msg = l.truncate(l.redact(e.msg))
+	if !l.filter(e) {
+		return
+	}
+	if l.sampler != nil && e.level != LevelFatal {
+		keep, dropped := l.sampler.sample(e)
+		if dropped > 0 {
+			l.emitSampledOut(dropped)
+		}
+		if !keep {
+			return
+		}
+	}
+	if l.dedup != nil && !l.dedup.check(l, e) {
+		return
+	}
+
+	l.emit(e)
+}
+
+// defaultErrorHandler reports write failures on stderr and carries on
+func defaultErrorHandler(err error) {
+	fmt.Fprintf(os.Stderr, "llog: failed to write log: %v\n", err)
+}
+
+// handleError passes err to the configured error handler. It is never
+// called with a writer lock held, so the handler may log itself.
+func (l *Logger) handleError(err error) {
+	if l.errHandler != nil {
+		l.errHandler(err)
+		return
+	}
+	defaultErrorHandler(err)
+}
+
+// writer returns the destination for lines at level
+func (l *Logger) writer(level Level) *mutexWriter {
+	if w, ok := l.levelOut[level]; ok {
+		return w
+	}
+	return l.out
+}
+
+// emit encodes e and writes it out
+func (l *Logger) emit(e *entry) {
+	if l.writerFunc != nil {
+		l.writerFunc(e.level, e.msg)
+		l.hooks.Load().fire(l, e.level, e.msg)
 		return
 	}
 
-	buf := bufPool.New().(*[]byte)
+	if l.utc {
+		e.time = e.time.UTC()
+	}
+	if l.fileAndLine || l.callerFunc {
+		l.resolveCaller(e)
+	}
+	if l.stackTrace && e.level <= l.stackLevel {
+		e.stack = captureStack()
+	}
+
+	buf := bufPool.Get().(*[]byte)
 	defer bufPool.Put(buf)
 
+	l.write(buf, e, l.writer(e.level), l.format)
+	for _, t := range l.targets {
+		if e.level <= t.minLevel {
+			l.write(buf, e, t.out, t.format)
+		}
+	}
+
+	l.hooks.Load().fire(l, e.level, e.msg)
+}
+
+// write encodes e in format into buf and writes it to w
+func (l *Logger) write(buf *[]byte, e *entry, w *mutexWriter, format Format) {
 	*buf = (*buf)[:0]
-	l.formatHeader(buf, level)
-	*buf = append(*buf, s...)
-	if len(s) == 0 || s[len(s)-1] != '\n' {
-		*buf = append(*buf, '\n')
+	switch format {
+	case FormatJSON:
+		l.formatJSON(buf, e)
+	case FormatOTel:
+		l.formatOTel(buf, e)
+	case FormatLogfmt:
+		l.formatLogfmt(buf, e)
+	default:
+		e.color = l.useColor(w)
+		l.formatText(buf, e)
 	}
 
-	_, err := l.out.Write(*buf)
+	var err error
+	if l.noLock {
+		_, err = writeLevel(w.Writer, e.level, *buf)
+	} else {
+		_, err = w.WriteLevel(e.level, *buf)
+	}
 	if err != nil {
-		panic(err)
+		l.handleError(err)
+	}
+}
+
+// Clone returns a copy of l with independent configuration. The copy
+// shares the writers of l, mutex included, so writes from all clones
+// stay serialized, as well as its sampling and dedup state and the highest
+// level seen. It starts with the hooks of l, but hooks added to either
+// afterwards aren't shared.
+// Configuration such as tag, level and flags is copied and can be
+// changed without affecting l.
+func (l *Logger) Clone() *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := loggerPool.Get().(*Logger)
+	*clone = Logger{
+		out:          l.out,
+		levelOut:     l.levelOut,
+		targets:      l.targets,
+		noLock:       l.noLock,
+		tags:         l.tags,
+		tagSep:       l.tagSep,
+		fieldSep:     l.fieldSep,
+		tag:          l.tag,
+		tagHeader:    l.tagHeader,
+		fileAndLine:  l.fileAndLine,
+		stackTrace:   l.stackTrace,
+		stackLevel:   l.stackLevel,
+		callerSkip:   l.callerSkip,
+		shortCaller:  l.shortCaller,
+		callerTrim:   l.callerTrim,
+		callerFilter: l.callerFilter,
+		callerFunc:   l.callerFunc,
+		timeFormat:   l.timeFormat,
+		hideLevel:    l.hideLevel,
+		utc:          l.utc,
+		maxMsgLen:    l.maxMsgLen,
+		multiline:    l.multiline,
+		prefix:       l.prefix,
+		suffix:       l.suffix,
+		terminator:   l.terminator,
+		fields:       l.fields,
+		groups:       l.groups,
+		groupPrefix:  l.groupPrefix,
+		format:       l.format,
+		color:        l.color,
+		writerFunc:   l.writerFunc,
+		errHandler:   l.errHandler,
+		filters:      l.filters,
+		redactors:    l.redactors,
+		sampler:      l.sampler,
+		dedup:        l.dedup,
+		seen:         l.seen,
+	}
+	clone.setLevel(l.Level())
+	clone.hooks.Store(l.hooks.Load())
+	return clone
+}
+
+// DefaultTagSeparator joins nested tags unless changed by WithTagSeparator
+const DefaultTagSeparator = ":"
+
+func singleTag(tag string) []string {
+	if tag == "" {
+		return nil
 	}
+	return []string{tag}
 }
 
-func (l *Logger) clone() *Logger {
-	return &Logger{
-		out:         l.out,
-		tag:         l.tag,
-		level:       l.level,
-		fileAndLine: l.fileAndLine,
+// setTags sets the tags of l and precomputes their header bytes, so it
+// must be called again when the tag or field separator changes
+func (l *Logger) setTags(tags []string) {
+	l.tags = tags
+	l.tag = strings.Join(tags, l.tagSep)
+	l.tagHeader = nil
+	if l.tag != "" {
+		l.tagHeader = append(append(append(l.tagHeader, '['), l.tag...), ']')
+		l.tagHeader = append(l.tagHeader, l.headerSep()...)
 	}
 }
 
+// WithTag returns a logger with tag nested under the current tags, so
+// WithTag("http").WithTag("auth") prints [http:auth]
 func (l *Logger) WithTag(tag string) *Logger {
-	clone := l.clone()
-	clone.tag = tag
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	if tag != "" {
+		// cap the slice so siblings never share a backing array
+		clone.setTags(append(l.tags[:len(l.tags):len(l.tags)], tag))
+	}
+	return clone
+}
+
+// WithTagReplace returns a logger whose tags are replaced by tag, which
+// is how WithTag used to behave. An empty tag removes all tags.
+func (l *Logger) WithTagReplace(tag string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.setTags(singleTag(tag))
+	return clone
+}
+
+// WithTagSeparator returns a logger joining nested tags with sep. A
+// separator of "][" renders them as [http][auth].
+func (l *Logger) WithTagSeparator(sep string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.tagSep = sep
+	clone.setTags(l.tags)
+	return clone
+}
+
+// WithMultilinePrefix returns a logger that, when enabled, repeats the
+// header, prefix and suffix on every line of a multi-line text message,
+// for parsers expecting a header per line:
+//
+//	l.WithMultilinePrefix(true).Info("a\nb")
+//	// 2006/01/02 15:04:05.000 [I]a
+//	// 2006/01/02 15:04:05.000 [I]b
+//
+// By default continuation lines are printed bare. A trailing newline
+// still doesn't add an empty line. Stack traces are left as is.
+func (l *Logger) WithMultilinePrefix(enabled bool) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.multiline = enabled
+	return clone
+}
+
+// truncatedMarker is appended to messages cut by WithMaxMessageLength
+const truncatedMarker = "…(truncated)"
+
+// WithMaxMessageLength returns a logger that cuts messages longer than n
+// bytes to at most n, never splitting a UTF-8 sequence, and appends
+// "…(truncated)". Header, fields and stack are left alone. A value of n
+// below 1 removes the limit, the default.
+func (l *Logger) WithMaxMessageLength(n int) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.maxMsgLen = max(n, 0)
+	return clone
+}
+
+func (l *Logger) truncate(msg string) string {
+	if l.maxMsgLen == 0 || len(msg) <= l.maxMsgLen {
+		return msg
+	}
+
+	i := l.maxMsgLen
+	for i > 0 && !utf8.RuneStart(msg[i]) {
+		i--
+	}
+	return msg[:i] + truncatedMarker
+}
+
+// WithFieldSeparator returns a logger that puts sep between the header
+// tokens of text lines, and between the header and the message, for
+// tools splitting on a fixed delimiter:
+//
+//	l.WithFieldSeparator("\t").Info("ready") // 2006/01/02 15:04:05.000\t[I]\tready
+//
+// By default tokens are separated by a space, and the level token is
+// followed directly by the rest of the line.
+func (l *Logger) WithFieldSeparator(sep string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.fieldSep = sep
+	clone.setTags(l.tags)
+	return clone
+}
+
+// WithPrefix returns a logger that inserts s between the header and the
+// message of every line, unbracketed. Text format only.
+func (l *Logger) WithPrefix(s string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.prefix = s
+	return clone
+}
+
+// WithSuffix returns a logger that appends s to the message of every
+// line, before the newline. Text format only.
+func (l *Logger) WithSuffix(s string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.suffix = s
+	return clone
+}
+
+// WithTerminator returns a logger ending every line with term instead of
+// "\n", such as "\r\n" or "\x00". With an empty term nothing is appended,
+// for sinks doing their own framing.
+func (l *Logger) WithTerminator(term string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.terminator = term
 	return clone
 }
 
 func (l *Logger) WithLevel(level Level) *Logger {
-	clone := l.clone()
-	clone.level = level
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.setLevel(level)
 	return clone
 }
 
 func (l *Logger) WithOutput(out io.Writer) *Logger {
-	clone := l.clone()
-	clone.out = &mutexWriter{
-		Writer: out,
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.out = newMutexWriter(out)
+	return clone
+}
+
+// WithLevelOutput returns a logger that writes lines at level to out
+// instead of the main output. Levels without an override keep using the
+// writer set by WithOutput, including ones set after this call.
+func (l *Logger) WithLevelOutput(level Level, out io.Writer) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.levelOut = make(map[Level]*mutexWriter, len(l.levelOut)+1)
+	for lv, w := range l.levelOut {
+		clone.levelOut[lv] = w
+	}
+	clone.levelOut[level] = newMutexWriter(out)
+	return clone
+}
+
+// WithWriterFunc returns a logger handing each enabled line's level and
+// message to fn instead of writing it, such as to collect lines in a test.
+// Since nothing is encoded, format, header and caller options are ignored.
+func (l *Logger) WithWriterFunc(fn func(level Level, msg string)) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.writerFunc = fn
+	return clone
+}
+
+// WithUnsafeNoLock returns a logger that writes without taking the lock
+// that serializes writes to its outputs, saving the mutex overhead for
+// tools that log from a single goroutine.
+//
+// This is dangerous: the caller must guarantee that nothing writes to the
+// same outputs concurrently, through this logger, other loggers sharing
+// them or the outputs directly. Otherwise lines interleave and writers
+// that aren't safe for concurrent use, such as a bytes.Buffer, corrupt.
+func (l *Logger) WithUnsafeNoLock() *Logger {
+	if l == nil {
+		return nil
 	}
+
+	clone := l.Clone()
+	clone.noLock = true
 	return clone
 }
 
 func (l *Logger) WithFileAndLine(included bool) *Logger {
-	clone := l.clone()
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
 	clone.fileAndLine = included
 	return clone
 }
 
+// WithTimeFormat returns a logger that formats timestamps with the given
+// time layout, such as one of the TimeFormat constants. An empty layout
+// disables the timestamp entirely.
+func (l *Logger) WithTimeFormat(layout string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.timeFormat = layout
+	return clone
+}
+
+// WithUTC returns a logger that prints timestamps in UTC rather than local
+// time. UTC is recommended for distributed systems so that lines from hosts
+// in different timezones can be correlated.
+func (l *Logger) WithUTC(utc bool) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.utc = utc
+	return clone
+}
+
+// WithShortCaller returns a logger that prints only the base file name
+// instead of the full path when file and line are included
+func (l *Logger) WithShortCaller(short bool) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.shortCaller = short
+	return clone
+}
+
+// WithCallerTrimPrefix returns a logger that removes prefix from caller
+// paths, so that with the module root they read internal/svc/handler.go:42,
+// which editors and terminals link to the file:
+//
+//	l = l.WithCallerTrimPrefix("/home/me/src/myapp/")
+//
+// Paths not starting with prefix are printed in full. WithShortCaller
+// takes precedence. An empty prefix restores full paths, the default.
+func (l *Logger) WithCallerTrimPrefix(prefix string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.callerTrim = prefix
+	return clone
+}
+
+// WithCallerFunc returns a logger that includes the fully-qualified
+// function name of the call site after file and line
+func (l *Logger) WithCallerFunc(included bool) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.callerFunc = included
+	return clone
+}
+
+// WithCallerSkip returns a logger that skips n extra stack frames when
+// resolving file and line, for use from within logging helpers.
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.callerSkip = l.callerSkip + n
+	return clone
+}
+
+// WithFields returns a logger that appends fields to every line as
+// key=value pairs, in key order after any fields already set. A key that
+// is already set keeps its position and takes the new value.
+//
+// fields is copied, so changing the map afterwards doesn't affect the
+// logger, and every With method adding fields builds a new set, so
+// siblings derived from one parent never see each other's fields.
+func (l *Logger) WithFields(fields Fields) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.fields = l.addFields(fields.toFields())
+	return clone
+}
+
+// With returns a logger with the typed fields attached in the given order.
+// It renders the same as WithFields without building a map:
+//
+//	l.With(llog.String("user", id), llog.Int("attempt", n)).Info("login")
+func (l *Logger) With(fields ...Field) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.fields = l.addFields(fields)
+	return clone
+}
+
+// Group returns a logger that places the fields added to it afterwards
+// in the group name, like slog.Logger.WithGroup. Text output prefixes
+// their keys with "name.", JSON output nests them in a "name" object.
+// Groups stack:
+//
+//	l.Group("http").Group("req").With(llog.String("method", "GET"))
+//	// text: http.req.method=GET
+//	// JSON: "http":{"req":{"method":"GET"}}
+//
+// An empty name adds no group.
+func (l *Logger) Group(name string) *Logger {
+	if l == nil {
+		return nil
+	}
+	if name == "" {
+		return l.Clone()
+	}
+
+	clone := l.Clone()
+	clone.groups = append(l.groups[:len(l.groups):len(l.groups)], name)
+	clone.groupPrefix = l.groupPrefix + name + "."
+	return clone
+}
+
+// ErrorKey is the field key WithError stores the error under
+const ErrorKey = "error"
+
+// WithError returns a logger with err attached as the ErrorKey field,
+// rendered by its Error method. A nil err adds no field.
+//
+//	l.WithError(err).Error("failed to process")
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l.Clone()
+	}
+
+	return l.With(Any(ErrorKey, err))
+}
+
+// CodeKey is the field key WithCode stores the code under
+const CodeKey = "code"
+
+// WithCode returns a logger with code attached as the CodeKey field, a
+// top-level key in JSON output for alerting rules to match on. It
+// combines with WithError:
+//
+//	l.WithError(err).WithCode("E_TIMEOUT").Error("upstream failed")
+//
+// The code stays top-level inside a Group. An empty code adds no field.
+func (l *Logger) WithCode(code string) *Logger {
+	if l == nil {
+		return nil
+	}
+	if code == "" {
+		return l.Clone()
+	}
+
+	clone := l.Clone()
+	clone.fields = l.addFieldsIn(nil, "", []Field{String(CodeKey, code)})
+	return clone
+}
+
+// WithColor returns a logger that colors the level token with ANSI
+// escapes according to mode. Color is never applied to JSON output.
+func (l *Logger) WithColor(mode ColorMode) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.color = mode
+	return clone
+}
+
+// WithErrorHandler returns a logger that passes write errors to fn.
+// By default a short notice is printed to stderr; install a handler
+// that panics to restore the old fail-hard behavior.
+func (l *Logger) WithErrorHandler(fn func(error)) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.errHandler = fn
+	return clone
+}
+
+// WithFormat returns a logger that encodes lines with format
+func (l *Logger) WithFormat(format Format) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.format = format
+	return clone
+}
+
+// Log logs at level, which may be a built-in or a registered level, for
+// when the level is only known at runtime
+func (l *Logger) Log(level Level, v ...any) {
+	if l.enabled(level) {
+		l.output(level, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) Logf(level Level, format string, v ...any) {
+	if l.enabled(level) {
+		l.output(level, fmt.Sprintf(format, v...))
+	}
+}
+
 func (l *Logger) Error(v ...any) {
-	l.output(LevelError, fmt.Sprint(v...))
+	if l.enabled(LevelError) {
+		l.output(LevelError, fmt.Sprint(v...))
+	}
 }
 
 func (l *Logger) Errorf(format string, v ...any) {
-	l.output(LevelError, fmt.Sprintf(format, v...))
+	if l.enabled(LevelError) {
+		l.output(LevelError, fmt.Sprintf(format, v...))
+	}
 }
 
 func (l *Logger) Warn(v ...any) {
-	l.output(LevelWarning, fmt.Sprint(v...))
+	if l.enabled(LevelWarn) {
+		l.output(LevelWarn, fmt.Sprint(v...))
+	}
 }
 
 func (l *Logger) Warnf(format string, v ...any) {
-	l.output(LevelWarning, fmt.Sprintf(format, v...))
+	if l.enabled(LevelWarn) {
+		l.output(LevelWarn, fmt.Sprintf(format, v...))
+	}
+}
+
+// Warning is an alias of Warn
+func (l *Logger) Warning(v ...any) {
+	if l.enabled(LevelWarn) {
+		l.output(LevelWarn, fmt.Sprint(v...))
+	}
+}
+
+// Warningf is an alias of Warnf
+func (l *Logger) Warningf(format string, v ...any) {
+	if l.enabled(LevelWarn) {
+		l.output(LevelWarn, fmt.Sprintf(format, v...))
+	}
 }
 
 func (l *Logger) Info(v ...any) {
-	l.output(LevelInfo, fmt.Sprint(v...))
+	if l.enabled(LevelInfo) {
+		l.output(LevelInfo, fmt.Sprint(v...))
+	}
 }
 
 func (l *Logger) Infof(format string, v ...any) {
-	l.output(LevelInfo, fmt.Sprintf(format, v...))
+	if l.enabled(LevelInfo) {
+		l.output(LevelInfo, fmt.Sprintf(format, v...))
+	}
 }
 
 func (l *Logger) Debug(v ...any) {
-	l.output(LevelDebug, fmt.Sprint(v...))
+	if l.enabled(LevelDebug) {
+		l.output(LevelDebug, fmt.Sprint(v...))
+	}
 }
 
 func (l *Logger) Debugf(format string, v ...any) {
-	l.output(LevelDebug, fmt.Sprintf(format, v...))
+	if l.enabled(LevelDebug) {
+		l.output(LevelDebug, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *Logger) Trace(v ...any) {
+	if l.enabled(LevelTrace) {
+		l.output(LevelTrace, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) Tracef(format string, v ...any) {
+	if l.enabled(LevelTrace) {
+		l.output(LevelTrace, fmt.Sprintf(format, v...))
+	}
+}
+
+// Print logs at LevelInfo, like log.Print
+func (l *Logger) Print(v ...any) {
+	if l.enabled(LevelInfo) {
+		l.output(LevelInfo, fmt.Sprint(v...))
+	}
+}
+
+// Println logs at LevelInfo with spaces between operands, like log.Println
+func (l *Logger) Println(v ...any) {
+	if l.enabled(LevelInfo) {
+		s := fmt.Sprintln(v...)
+		l.output(LevelInfo, s[:len(s)-1])
+	}
+}
+
+// Printf logs at LevelInfo, like log.Printf
+func (l *Logger) Printf(format string, v ...any) {
+	if l.enabled(LevelInfo) {
+		l.output(LevelInfo, fmt.Sprintf(format, v...))
+	}
+}
+
+// Fatal logs at LevelFatal, flushes the outputs so the line isn't lost in
+// a buffered or async writer, and exits the process with status 1. The
+// exit happens even when the logger is set to LevelOff.
+func (l *Logger) Fatal(v ...any) {
+	if l.enabled(LevelFatal) {
+		l.output(LevelFatal, fmt.Sprint(v...))
+	}
+	l.exit()
+}
+
+func (l *Logger) Fatalf(format string, v ...any) {
+	if l.enabled(LevelFatal) {
+		l.output(LevelFatal, fmt.Sprintf(format, v...))
+	}
+	l.exit()
+}
+
+// nowFunc timestamps lines, see SetClock
+var nowFunc = time.Now
+
+// SetClock replaces time.Now as the source of line timestamps and of the
+// interval boundaries of NewTimeRotatingFile, so tests can freeze time and
+// compare complete lines or golden files:
+//
+//	llog.SetClock(func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) })
+//	defer llog.SetClock(nil)
+//
+// It is a testing hook; a nil now restores time.Now. It isn't safe to call
+// while other goroutines log.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	nowFunc = now
+}
+
+// exitFunc is called by Fatal, see SetExitFunc
+var exitFunc = os.Exit
+
+// SetExitFunc replaces os.Exit as the function Fatal calls with status 1,
+// so tests can cover fatal paths without ending the process:
+//
+//	var code int
+//	llog.SetExitFunc(func(c int) { code = c })
+//	defer llog.SetExitFunc(nil)
+//
+// Fatal returns normally if fn does. A nil fn restores os.Exit. It isn't
+// safe to call while other goroutines log.
+func SetExitFunc(fn func(code int)) {
+	if fn == nil {
+		fn = os.Exit
+	}
+	exitFunc = fn
+}
+
+// exit flushes l and exits with status 1, as the last step of Fatal
+func (l *Logger) exit() {
+	l.Flush()
+	exitFunc(1)
+}
+
+// Panic logs at LevelError, flushes the outputs like Fatal, then panics
+// with the logged message
+func (l *Logger) Panic(v ...any) {
+	s := fmt.Sprint(v...)
+	l.output(LevelError, s)
+	l.Flush()
+	panic(s)
+}
+
+func (l *Logger) Panicf(format string, v ...any) {
+	s := fmt.Sprintf(format, v...)
+	l.output(LevelError, s)
+	l.Flush()
+	panic(s)
 }
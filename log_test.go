@@ -0,0 +1,499 @@
+package llog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestPanicFlushes(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(NewBufferedWriter(&buf, time.Hour, 1<<20)))
+
+	defer func() {
+		v := recover()
+		if v != "broken 42" {
+			t.Fatalf("panic value = %#v", v)
+		}
+		if !strings.Contains(buf.String(), "[E]broken 42\n") {
+			t.Fatalf("output = %q", buf.String())
+		}
+	}()
+	l.Panicf("broken %d", 42)
+}
+
+func TestOutputAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+	l := New(OptionOutput(nopWriter{}))
+	n := 42
+
+	// only the message itself is allocated, the line buffer is pooled
+	if allocs := testing.AllocsPerRun(1000, func() { l.Infof("n=%d", n) }); allocs > 1 {
+		t.Fatalf("Infof allocates %v times per line, want at most 1", allocs)
+	}
+}
+
+func BenchmarkInfof(b *testing.B) {
+	l := New(OptionOutput(nopWriter{}))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Infof("request %d handled", i)
+	}
+}
+
+// fixedTime is the clock used by tests comparing complete lines
+var fixedTime = time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.UTC)
+
+func TestWithTimeFormat(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(func() time.Time { return fixedTime })
+
+	tests := []struct {
+		name   string
+		layout string
+		want   string
+	}{
+		{"default", DefaultTimeFormat, "2024/01/02 03:04:05.006 [I]hello\n"},
+		{"RFC3339", TimeFormatRFC3339, "2024-01-02T03:04:05Z [I]hello\n"},
+		{"custom", "Jan _2 15:04", "Jan  2 03:04 [I]hello\n"},
+		{"unix millis", TimeFormatUnixMillis, "1704164645006 [I]hello\n"},
+		{"disabled", "", "[I]hello\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithUTC(true).WithTimeFormat(tt.layout).Info("hello")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).WithTimeFormat("")
+
+	l.Trace("hidden")
+	l.Tracef("hidden %d", 1)
+	if buf.Len() != 0 {
+		t.Fatalf("trace printed at the default level: %q", buf.String())
+	}
+
+	l = l.WithLevel(LevelTrace)
+	l.Trace("a")
+	l.Debug("b")
+	l.Tracef("c%d", 1)
+	if got, want := buf.String(), "[T]a\n[D]b\n[T]c1\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	if err := l.SetLevelString("t"); err != nil || l.Level() != LevelTrace {
+		t.Fatalf("SetLevelString(t) = %v, level %d", err, l.Level())
+	}
+}
+
+func TestLevelOff(t *testing.T) {
+	defer SetExitFunc(nil)
+	var exits []int
+	SetExitFunc(func(code int) { exits = append(exits, code) })
+
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf), OptionLevel(LevelOff))
+	for _, level := range []Level{LevelFatal, LevelError, LevelWarn, LevelInfo, LevelDebug, LevelTrace} {
+		l.Log(level, "x")
+		if l.Enabled(level) {
+			t.Errorf("Enabled(%d) at LevelOff", level)
+		}
+	}
+	l.Error("x")
+	l.Info("x")
+	l.Fatal("x")
+	l.Fatalf("x")
+
+	if buf.Len() != 0 {
+		t.Fatalf("output at LevelOff = %q", buf.String())
+	}
+	if len(exits) != 2 || exits[0] != 1 || exits[1] != 1 {
+		t.Fatalf("Fatal exits = %v, want [1 1]", exits)
+	}
+}
+
+// TestLevelRace toggles the level of a logger while other goroutines log
+// through it; run with -race
+func TestLevelRace(t *testing.T) {
+	l := New(OptionOutput(&syncBuffer{}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				l.Debug("d")
+				l.Info("i")
+				_ = l.Level()
+			}
+		}()
+	}
+	for j := 0; j < 200; j++ {
+		if err := l.SetLevelString("debug"); err != nil {
+			t.Fatal(err)
+		}
+		l.WithTemporaryLevel(LevelError)()
+	}
+	wg.Wait()
+}
+
+func TestWithTerminator(t *testing.T) {
+	tests := []struct {
+		name string
+		term string
+		msgs []string
+		want string
+	}{
+		{"default", "\n", []string{"a", "b\n"}, "[I]a\n[I]b\n"},
+		{"empty", "", []string{"a", "b\n"}, "[I]a[I]b\n"},
+		{"CRLF", "\r\n", []string{"a", "b\r\n"}, "[I]a\r\n[I]b\r\n"},
+		{"NUL", "\x00", []string{"a"}, "[I]a\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(OptionOutput(&buf)).WithTimeFormat("").WithTerminator(tt.term)
+			for _, msg := range tt.msgs {
+				l.Info(msg)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	var l *Logger
+
+	// every exported method, called with zero arguments, or pointers to
+	// zero values
+	v := reflect.ValueOf(l)
+	typ := v.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		t.Run(m.Name, func(t *testing.T) {
+			if m.Name == "Panic" || m.Name == "Panicf" {
+				defer func() { recover() }()
+			}
+			if m.Name == "Fatal" || m.Name == "Fatalf" {
+				defer SetExitFunc(nil)
+				SetExitFunc(func(int) {})
+			}
+
+			ft := m.Type
+			args := []reflect.Value{v}
+			for j := 1; j < ft.NumIn(); j++ {
+				if in := ft.In(j); in.Kind() == reflect.Pointer {
+					args = append(args, reflect.New(in.Elem()))
+				} else {
+					args = append(args, reflect.Zero(in))
+				}
+			}
+			var out []reflect.Value
+			if ft.IsVariadic() {
+				out = m.Func.CallSlice(args)
+			} else {
+				out = m.Func.Call(args)
+			}
+			for _, o := range out {
+				if o.Kind() == reflect.Func && !o.IsNil() {
+					o.Call(nil)
+				}
+			}
+		})
+	}
+
+	fmt.Fprintln(l.Writer(LevelInfo), "x")
+	fmt.Fprintln(l.DefaultWriter(), "x")
+	if l.WithTag("a").WithFields(Fields{"k": 1}) != nil {
+		t.Fatal("With methods of a nil logger returned non-nil")
+	}
+	if l.Group("x") != nil {
+		t.Fatal("Group of a nil logger returned non-nil")
+	}
+}
+
+func TestFormatHeader(t *testing.T) {
+	l := New(OptionFileAndLine(true)).WithTag("http").WithUTC(true)
+	e := entry{level: LevelInfo, time: fixedTime, file: "/src/app/handler.go", line: 42}
+
+	var buf []byte
+	l.formatHeader(&buf, &e)
+	if got, want := string(buf), "2024/01/02 03:04:05.006 [I][http] /src/app/handler.go:42 "; got != want {
+		t.Fatalf("header = %q, want %q", got, want)
+	}
+
+	buf = make([]byte, 0, 1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = buf[:0]
+		l.formatHeader(&buf, &e)
+	})
+	if allocs != 0 {
+		t.Fatalf("formatHeader allocates %v times", allocs)
+	}
+}
+
+func BenchmarkFormatHeader(b *testing.B) {
+	l := New(OptionFileAndLine(true)).WithTag("http")
+	e := entry{level: LevelInfo, time: fixedTime, file: "/src/app/handler.go", line: 42}
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = buf[:0]
+		l.formatHeader(&buf, &e)
+	}
+}
+
+func BenchmarkUnsafeNoLock(b *testing.B) {
+	l := New(OptionOutput(nopWriter{}))
+	b.Run("Locked", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l.Info("single goroutine")
+		}
+	})
+	b.Run("NoLock", func(b *testing.B) {
+		nl := l.WithUnsafeNoLock()
+		for i := 0; i < b.N; i++ {
+			nl.Info("single goroutine")
+		}
+	})
+}
+
+func TestTagHeader(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).WithTimeFormat("").WithTag("http")
+	l.WithTag("auth").Info("a")
+	l.WithTag("auth").WithTagSeparator("][").Info("b")
+	l.WithFieldSeparator("|").Info("c")
+	l.WithTagReplace("").Info("d")
+	New(OptionOutput(&buf)).WithTimeFormat("").WithTag("a").WithTag("b").WithTag("c").Info("e")
+
+	want := "[I][http:auth] a\n[I][http][auth] b\n[I]|[http]|c\n[I]d\n[I][a:b:c] e\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	defer Reset()
+	buf.Reset()
+	SetOutput(&buf)
+	SetTimeFormat("")
+	SetTag("one")
+	Info("x")
+	SetTag("two")
+	Info("y")
+	if got, want := buf.String(), "[I][one] x\n[I][two] y\n"; got != want {
+		t.Fatalf("after SetTag output = %q, want %q", got, want)
+	}
+}
+
+func BenchmarkTaggedInfo(b *testing.B) {
+	l := New(OptionOutput(nopWriter{})).WithTag("http").WithTag("auth")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("tagged")
+	}
+}
+
+func BenchmarkFileAndLine(b *testing.B) {
+	l := New(OptionOutput(nopWriter{}))
+	b.Run("Off", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l.Info("hot loop")
+		}
+	})
+	b.Run("On", func(b *testing.B) {
+		fl := l.WithFileAndLine(true)
+		for i := 0; i < b.N; i++ {
+			fl.Info("hot loop")
+		}
+	})
+}
+
+// BenchmarkLookupCaller compares the cached frame lookup with resolving
+// the pc every time
+func BenchmarkLookupCaller(b *testing.B) {
+	pc := callerPC(0)
+	b.Run("Cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lookupCaller(pc)
+		}
+	})
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			runtime.CallersFrames([]uintptr{pc}).Next()
+		}
+	})
+}
+
+func TestWithMaxMessageLength(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		msg  string
+		want string
+	}{
+		{"ascii", 5, strings.Repeat("abcdefgh", 100), "abcde…(truncated)"},
+		{"multibyte", 4, strings.Repeat("日本語", 100), "日…(truncated)"},
+		{"multibyte boundary", 6, strings.Repeat("日本語", 100), "日本…(truncated)"},
+		{"exact", 5, "abcde", "abcde"},
+		{"no limit", 0, strings.Repeat("x", 64), strings.Repeat("x", 64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithTimeFormat("").WithMaxMessageLength(tt.n).Info(tt.msg)
+			if got, want := buf.String(), "[I]"+tt.want+"\n"; got != want {
+				t.Fatalf("output = %q, want %q", got, want)
+			}
+			if !utf8.Valid(buf.Bytes()) {
+				t.Fatalf("output isn't valid UTF-8: %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestDiscardWithHook(t *testing.T) {
+	hook := &recordHook{levels: []Level{LevelInfo}}
+	l := New(OptionOutput(io.Discard)).WithHook(hook)
+
+	if !l.Enabled(LevelInfo) {
+		t.Fatal("Enabled is false with a hook registered")
+	}
+	l.Infof("fired %d", 1)
+	if len(hook.msgs) != 1 || hook.msgs[0] != "fired 1" {
+		t.Fatalf("hook messages = %q", hook.msgs)
+	}
+}
+
+// BenchmarkDiscard compares a line dropped because the output is io.Discard
+// with one formatted for a writer that ignores it
+func BenchmarkDiscard(b *testing.B) {
+	b.Run("Discard", func(b *testing.B) {
+		l := New(OptionOutput(io.Discard))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Infof("request %d took %v", i, time.Millisecond)
+		}
+	})
+	b.Run("Writer", func(b *testing.B) {
+		l := New(OptionOutput(nopWriter{}))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Infof("request %d took %v", i, time.Millisecond)
+		}
+	})
+}
+
+// restoreConcurrently calls restore from several goroutines at once
+func restoreConcurrently(restore func()) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			restore()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithTemporaryLevel(t *testing.T) {
+	l := New(OptionOutput(nopWriter{}), OptionLevel(LevelInfo))
+
+	restore := l.WithTemporaryLevel(LevelDebug)
+	if l.Level() != LevelDebug {
+		t.Fatalf("level = %v, want debug", l.Level())
+	}
+	restoreConcurrently(restore)
+	if l.Level() != LevelInfo {
+		t.Fatalf("level after restore = %v, want info", l.Level())
+	}
+
+	if err := l.SetLevelString("warn"); err != nil {
+		t.Fatal(err)
+	}
+	restore()
+	if l.Level() != LevelWarn {
+		t.Fatalf("second restore changed the level to %v", l.Level())
+	}
+}
+
+func TestDerivedFieldsIndependent(t *testing.T) {
+	var buf bytes.Buffer
+	parent := New(OptionOutput(&buf)).WithTimeFormat("").
+		With(String("a", "1")).With(String("b", "2")).With(String("c", "3"))
+
+	left := parent.With(String("side", "left"))
+	right := parent.With(String("side", "right"))
+	left = left.WithFields(Fields{"only": "left"})
+
+	tests := []struct {
+		name string
+		l    *Logger
+		want string
+	}{
+		{"left", left, "[I]a=1 b=2 c=3 side=left only=left msg\n"},
+		{"right", right, "[I]a=1 b=2 c=3 side=right msg\n"},
+		{"parent", parent, "[I]a=1 b=2 c=3 msg\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.l.Info("msg")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFieldSeparator(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(func() time.Time { return fixedTime })
+
+	tests := []struct {
+		name string
+		sep  string
+		tag  string
+		want string
+	}{
+		{"tab", "\t", "http", "2024/01/02 03:04:05.006\t[I]\t[http]\tk=v\tmsg\n"},
+		{"pipe", "|", "http", "2024/01/02 03:04:05.006|[I]|[http]|k=v|msg\n"},
+		{"tab without tag", "\t", "", "2024/01/02 03:04:05.006\t[I]\tk=v\tmsg\n"},
+		{"pipe without tag", "|", "", "2024/01/02 03:04:05.006|[I]|k=v|msg\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(OptionOutput(&buf)).WithUTC(true).WithTag(tt.tag).WithFieldSeparator(tt.sep).
+				With(String("k", "v")).Info("msg")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	New(OptionOutput(&buf)).WithTimeFormat("").WithFieldSeparator("|").Info("msg")
+	if got, want := buf.String(), "[I]|msg\n"; got != want {
+		t.Fatalf("header-only output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package llog
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// formatLogfmt encodes e as logfmt key=value pairs followed by the
+// terminator
+func (l *Logger) formatLogfmt(buf *[]byte, e *entry) {
+	if l.timeFormat != "" {
+		appendLogfmtPair(buf, "ts", string(appendTime(nil, e.time, l.timeFormat)))
+	}
+	appendLogfmtPair(buf, "level", e.level.name())
+	if l.tag != "" {
+		appendLogfmtPair(buf, "tag", l.tag)
+	}
+	if l.fileAndLine {
+		appendLogfmtPair(buf, "caller", e.file+":"+strconv.Itoa(e.line))
+	}
+	if l.callerFunc {
+		appendLogfmtPair(buf, "func", e.fn)
+	}
+	appendLogfmtPair(buf, "msg", e.msg)
+	for _, f := range l.fields {
+		appendLogfmtField(buf, f.Key, l.fieldValue(f))
+	}
+	if e.stack != "" {
+		appendLogfmtPair(buf, "stack", e.stack)
+	}
+
+	// drop the space after the last pair
+	*buf = (*buf)[:len(*buf)-1]
+	*buf = append(*buf, l.terminator...)
+}
+
+// appendLogfmtField appends a pair for a field value, encoding common
+// types directly and others with fmt
+func appendLogfmtField(buf *[]byte, key string, v any) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case int, int64, bool, float64:
+		appendLogfmtKey(buf, key)
+		*buf = append(*buf, '=')
+		appendFieldValue(buf, v)
+		*buf = append(*buf, ' ')
+		return
+	case error:
+		s = errorString(v)
+	default:
+		s = fmt.Sprint(v)
+	}
+	appendLogfmtPair(buf, key, s)
+}
+
+func appendLogfmtPair(buf *[]byte, key, value string) {
+	appendLogfmtKey(buf, key)
+	*buf = append(*buf, '=')
+	if logfmtNeedsQuote(value) {
+		*buf = strconv.AppendQuote(*buf, value)
+	} else {
+		*buf = append(*buf, value...)
+	}
+	*buf = append(*buf, ' ')
+}
+
+// appendLogfmtKey appends key with every character that logfmt doesn't
+// allow in keys, the ones logfmtNeedsQuote looks for, replaced by '_'.
+// An empty key is printed as "_".
+func appendLogfmtKey(buf *[]byte, key string) {
+	if !logfmtNeedsQuote(key) {
+		*buf = append(*buf, key...)
+		return
+	}
+	if key == "" {
+		*buf = append(*buf, '_')
+		return
+	}
+	for _, r := range key {
+		if logfmtBadRune(r) {
+			r = '_'
+		}
+		*buf = utf8.AppendRune(*buf, r)
+	}
+}
+
+// logfmtNeedsQuote reports whether s is empty or holds a space, '=', '"',
+// a control character or invalid UTF-8
+func logfmtNeedsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if logfmtBadRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func logfmtBadRune(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r)
+}
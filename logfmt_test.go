@@ -0,0 +1,28 @@
+package llog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFormatLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).WithTimeFormat("").WithFormat(FormatLogfmt).WithTag("http")
+
+	l.With(String("path", "/a b"), Int("status", 200), String("empty", "")).Info("done")
+	want := `level=info tag=http msg=done path="/a b" status=200 empty=""` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestFormatLogfmtKeys(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).WithTimeFormat("").WithFormat(FormatLogfmt)
+
+	l.WithFields(Fields{"a b": "x y", `q"=`: 1, "": true}).Info("keys")
+	want := `level=info msg=keys _=true a_b="x y" q__=1` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
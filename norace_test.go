@@ -0,0 +1,5 @@
+//go:build !race
+
+package llog
+
+const raceEnabled = false
@@ -0,0 +1,61 @@
+package llog
+
+import (
+	"io"
+	"os"
+)
+
+// Option configures a Logger built by New. Options are named OptionXxx
+// since the WithXxx names belong to the methods deriving a logger.
+type Option func(*Logger)
+
+// New returns a logger writing to stderr at LevelInfo, configured by opts
+func New(opts ...Option) *Logger {
+	l := &Logger{
+		out:        newMutexWriter(os.Stderr),
+		tagSep:     DefaultTagSeparator,
+		timeFormat: DefaultTimeFormat,
+		terminator: "\n",
+		seen:       newLevelSeen(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func OptionOutput(out io.Writer) Option {
+	return func(l *Logger) {
+		l.out = newMutexWriter(out)
+	}
+}
+
+func OptionLevel(level Level) Option {
+	return func(l *Logger) {
+		l.setLevel(level)
+	}
+}
+
+func OptionTag(tag string) Option {
+	return func(l *Logger) {
+		l.setTags(singleTag(tag))
+	}
+}
+
+func OptionFileAndLine(included bool) Option {
+	return func(l *Logger) {
+		l.fileAndLine = included
+	}
+}
+
+// NewDiscard returns a logger that prints nothing, returning from every
+// call before any formatting, except Panic, which still needs its message
+// as the panic value. Libraries accepting an optional *Logger can use it
+// instead of guarding each call:
+//
+//	if log == nil {
+//		log = llog.NewDiscard()
+//	}
+func NewDiscard() *Logger {
+	return New(OptionOutput(io.Discard), OptionLevel(LevelOff))
+}
@@ -0,0 +1,31 @@
+package llog
+
+import (
+	"testing"
+)
+
+func TestNewDiscard(t *testing.T) {
+	defer SetExitFunc(nil)
+	var code int
+	SetExitFunc(func(c int) { code = c })
+
+	l := NewDiscard()
+	var calls int
+	s := countingStringer{&calls}
+	l.Error(s)
+	l.Infof("%v", s)
+	l.Log(LevelWarn, s)
+	l.InfoIf(true, s)
+	l.WithTag("child").Debug(s)
+	l.Fatal(s)
+	if calls != 0 {
+		t.Fatalf("String called %d times", calls)
+	}
+	if code != 1 {
+		t.Fatalf("Fatal exit code = %d", code)
+	}
+
+	if n := testing.AllocsPerRun(100, func() { l.Info("nothing") }); n != 0 {
+		t.Fatalf("Info allocates %v times", n)
+	}
+}
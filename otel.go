@@ -0,0 +1,72 @@
+package llog
+
+import "strconv"
+
+// otelSeverity maps level to an OpenTelemetry severity number and text.
+// Custom levels fall into the bucket of the next built-in level.
+func otelSeverity(level Level) (int, string) {
+	switch {
+	case level <= LevelFatal:
+		return 21, "FATAL"
+	case level <= LevelError:
+		return 17, "ERROR"
+	case level <= LevelWarn:
+		return 13, "WARN"
+	case level <= LevelInfo:
+		return 9, "INFO"
+	case level <= LevelDebug:
+		return 5, "DEBUG"
+	}
+	return 1, "TRACE"
+}
+
+// formatOTel encodes e as a JSON object shaped like an OpenTelemetry log
+// record, followed by the terminator. Tag, caller and stack are attributes
+// with the semantic-convention keys where one exists.
+func (l *Logger) formatOTel(buf *[]byte, e *entry) {
+	num, text := otelSeverity(e.level)
+
+	*buf = append(*buf, `{"timestamp":`...)
+	*buf = strconv.AppendInt(*buf, e.time.UnixNano(), 10)
+	*buf = append(*buf, `,"severityNumber":`...)
+	*buf = strconv.AppendInt(*buf, int64(num), 10)
+	*buf = append(*buf, `,"severityText":`...)
+	appendJSONString(buf, text)
+	*buf = append(*buf, `,"body":`...)
+	appendJSONString(buf, e.msg)
+
+	*buf = append(*buf, `,"attributes":{`...)
+	n := 0
+	attr := func(k string) {
+		if n > 0 {
+			*buf = append(*buf, ',')
+		}
+		n++
+		appendJSONString(buf, k)
+		*buf = append(*buf, ':')
+	}
+	if l.tag != "" {
+		attr("tag")
+		appendJSONString(buf, l.tag)
+	}
+	if l.fileAndLine {
+		attr("code.filepath")
+		appendJSONString(buf, e.file)
+		attr("code.lineno")
+		*buf = strconv.AppendInt(*buf, int64(e.line), 10)
+	}
+	if l.callerFunc {
+		attr("code.function")
+		appendJSONString(buf, e.fn)
+	}
+	for _, f := range l.fields {
+		attr(f.Key)
+		appendJSONValue(buf, l.fieldValue(f))
+	}
+	if e.stack != "" {
+		attr("exception.stacktrace")
+		appendJSONString(buf, e.stack)
+	}
+	*buf = append(*buf, '}', '}')
+	*buf = append(*buf, l.terminator...)
+}
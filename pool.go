@@ -0,0 +1,28 @@
+package llog
+
+import "sync"
+
+// loggerPool recycles the loggers derived by Clone and the With methods
+var loggerPool = sync.Pool{
+	New: func() any { return new(Logger) },
+}
+
+// Release returns l to the pool reused by Clone and the With methods, to
+// save an allocation per short-lived derived logger such as a per-request
+// one:
+//
+//	rl := l.WithFields(llog.Fields{"request": id})
+//	defer rl.Release()
+//
+// After Release, l and anything still referencing it must not be used:
+// the struct will be handed out again as an unrelated logger. Loggers
+// derived from l stay valid. Release is a no-op on nil and on the default
+// logger.
+func (l *Logger) Release() {
+	if l == nil || l == std() {
+		return
+	}
+
+	*l = Logger{}
+	loggerPool.Put(l)
+}
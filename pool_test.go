@@ -0,0 +1,96 @@
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReleaseKeepsParent(t *testing.T) {
+	var buf bytes.Buffer
+	parent := New(OptionOutput(&buf))
+
+	children := []*Logger{
+		parent.WithError(nil),
+		parent.WithCode(""),
+		parent.Group(""),
+		parent.WithErrorChain(nil),
+		parent.WithFields(Fields{"k": "v"}),
+	}
+	for _, child := range children {
+		if child == parent {
+			t.Fatal("With method returned its receiver")
+		}
+		child.Release()
+	}
+
+	parent.Info("still usable")
+	if !strings.Contains(buf.String(), "still usable") {
+		t.Fatalf("parent output = %q", buf.String())
+	}
+}
+
+func TestReleaseDefault(t *testing.T) {
+	defer Reset()
+
+	Default().Release()
+	buf, done := Capture()
+	defer done()
+	Info("after release")
+	if !strings.Contains(buf.String(), "after release") {
+		t.Fatalf("output = %q", buf.String())
+	}
+}
+
+func TestReleaseWithDedup(t *testing.T) {
+	var buf syncBuffer
+	base := New(OptionOutput(&buf)).WithDedup(10 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		rl := base.WithFields(Fields{"request": i})
+		rl.Info("same")
+		rl.Release()
+	}
+
+	// the summary is printed by the timer after the loggers were released
+	time.Sleep(50 * time.Millisecond)
+	if !strings.Contains(buf.String(), "last message repeated 2 times") {
+		t.Fatalf("output = %q", buf.String())
+	}
+}
+
+func TestReleaseNil(t *testing.T) {
+	var l *Logger
+	l.Release()
+	if l.WithError(errors.New("x")) != nil {
+		t.Fatal("nil logger derived a non-nil one")
+	}
+}
+
+// BenchmarkRequestLogger derives a logger per request on every goroutine,
+// with and without returning it to the pool
+func BenchmarkRequestLogger(b *testing.B) {
+	base := New(OptionOutput(nopWriter{}))
+
+	b.Run("NoRelease", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				rl := base.WithTag("req")
+				rl.Info("handled")
+			}
+		})
+	})
+	b.Run("Release", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				rl := base.WithTag("req")
+				rl.Info("handled")
+				rl.Release()
+			}
+		})
+	})
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package llog
+
+// raceEnabled reports whether the race detector is on. It makes sync.Pool
+// drop items at random, so allocation counts aren't meaningful.
+const raceEnabled = true
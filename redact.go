@@ -0,0 +1,38 @@
+package llog
+
+import "regexp"
+
+// WithRedactor returns a logger that rewrites every message and string
+// field value with fn before it is filtered and written, in text and JSON
+// output alike. Repeated calls chain, earlier redactors running first.
+//
+// Redactors run on every line that passes the level check and field
+// values are redacted each time they are rendered, so keep fn cheap on
+// hot paths.
+func (l *Logger) WithRedactor(fn func(string) string) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.redactors = append(l.redactors[:len(l.redactors):len(l.redactors)], fn)
+	return clone
+}
+
+// WithRedactPattern returns a logger that replaces matches of re with
+// replacement, which may refer to submatches as in
+// regexp.Regexp.ReplaceAllString:
+//
+//	l = l.WithRedactPattern(regexp.MustCompile(`Bearer \S+`), "Bearer [REDACTED]")
+func (l *Logger) WithRedactPattern(re *regexp.Regexp, replacement string) *Logger {
+	return l.WithRedactor(func(s string) string {
+		return re.ReplaceAllString(s, replacement)
+	})
+}
+
+func (l *Logger) redact(s string) string {
+	for _, fn := range l.redactors {
+		s = fn(s)
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package llog
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWithRedactPattern(t *testing.T) {
+	bearer := regexp.MustCompile(`Bearer \S+`)
+	email := regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"text", FormatText, `[I]user=[email] auth="Bearer [REDACTED]" login by [email] with Bearer [REDACTED]` + "\n"},
+		{"JSON", FormatJSON, `{"level":"info","msg":"login by [email] with Bearer [REDACTED]","user":"[email]","auth":"Bearer [REDACTED]"}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(OptionOutput(&buf)).WithTimeFormat("").WithFormat(tt.format).
+				WithRedactPattern(bearer, "Bearer [REDACTED]").
+				WithRedactPattern(email, "[email]")
+
+			l.With(String("user", "ann@example.com"), String("auth", "Bearer abc.def")).
+				Info("login by ann@example.com with Bearer s3cr3t")
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRedactorChain(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).
+		WithRedactor(func(s string) string { return strings.ReplaceAll(s, "a", "b") }).
+		WithRedactor(func(s string) string { return strings.ReplaceAll(s, "b", "c") })
+
+	l.Info("a")
+	if !strings.HasSuffix(buf.String(), "[I]c\n") {
+		t.Fatalf("output = %q", buf.String())
+	}
+}
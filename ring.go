@@ -0,0 +1,74 @@
+package llog
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// RingBuffer keeps the most recent lines written to it in memory. It is
+// safe for concurrent use, so it can be an output of loggers while an HTTP
+// handler reads it back:
+//
+//	ring := llog.NewRingBuffer(1000)
+//	llog.SetOutput(llog.MultiWriter(os.Stderr, ring))
+//	http.HandleFunc("/debug/logs", func(w http.ResponseWriter, r *http.Request) {
+//		ring.Dump(w)
+//	})
+type RingBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	next  int // index of the oldest line once full
+	full  bool
+}
+
+// NewRingBuffer returns a RingBuffer holding up to capacity lines, evicting
+// the oldest first. A capacity below 1 is treated as 1.
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer{
+		lines: make([]string, capacity),
+	}
+}
+
+// Write stores p as one line, without its trailing newline. The logger
+// writes each entry in a single call, so multi-line entries such as stack
+// traces stay together.
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+
+	r.mu.Lock()
+	r.lines[r.next] = line
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+	r.mu.Unlock()
+	return len(p), nil
+}
+
+// Lines returns a copy of the buffered lines, oldest first
+func (r *RingBuffer) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	lines := make([]string, 0, len(r.lines))
+	lines = append(lines, r.lines[r.next:]...)
+	return append(lines, r.lines[:r.next]...)
+}
+
+// Dump writes the buffered lines to w, oldest first, one per line
+func (r *RingBuffer) Dump(w io.Writer) error {
+	for _, line := range r.Lines() {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package llog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RotateOption configures a rotating file writer
+type RotateOption func(*rotateConfig)
+
+type rotateConfig struct {
+	maxAge   time.Duration
+	compress bool
+}
+
+// RotateMaxAge makes a time rotating file remove files of periods that
+// started more than d ago
+func RotateMaxAge(d time.Duration) RotateOption {
+	return func(c *rotateConfig) {
+		c.maxAge = d
+	}
+}
+
+// RotateCompress makes a size rotating file gzip each backup in the
+// background once it is rotated out, producing path.1.gz and so on. A
+// backup waits as path.pending<n> until it is compressed. Compression
+// errors are returned by the next Write, or by Close, which waits for
+// compression in flight; a backup that fails to compress is kept as is.
+func RotateCompress() RotateOption {
+	return func(c *rotateConfig) {
+		c.compress = true
+	}
+}
+
+// rotatingFile is a file that is rotated once it grows past maxSize
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	config     rotateConfig
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// with RotateCompress, rotated files wait under a pending name while
+	// background jobs, run in rotation order, compress and shift them in
+	pending     int
+	lastJob     chan struct{}
+	compressing sync.WaitGroup
+	errMu       sync.Mutex
+	compressErr error
+}
+
+// NewRotatingFile returns a writer appending to the file at path. Once a
+// write would take the file past maxSizeBytes, it is renamed to path.1,
+// older backups are shifted up to path.<maxBackups>, the oldest is
+// removed, and a fresh file is opened. With RotateCompress, backups are
+// gzipped and maxBackups counts the compressed files too. A maxSizeBytes
+// below 1 means no limit, so the file is never rotated.
+func NewRotatingFile(path string, maxSizeBytes int64, maxBackups int, opts ...RotateOption) (io.WriteCloser, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSizeBytes,
+		maxBackups: maxBackups,
+	}
+	for _, opt := range opts {
+		opt(&f.config)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = fi.Size()
+	return nil
+}
+
+func (f *rotatingFile) backup(i int) string {
+	return f.path + "." + strconv.Itoa(i)
+}
+
+// remove deletes name, ignoring a missing file
+func remove(name string) error {
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// rename moves oldname to newname, ignoring a missing oldname
+func rename(oldname, newname string) error {
+	if err := os.Rename(oldname, newname); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// rotate moves the current file out of the way and opens a fresh one. The
+// file at path is reopened even if moving it fails, so a failed rotation
+// is retried on a later write instead of disabling the writer.
+func (f *rotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err == nil {
+		err = f.shift()
+	}
+	if oerr := f.open(); oerr != nil {
+		return errors.Join(err, oerr)
+	}
+	return err
+}
+
+// shift moves the closed file at path to the first backup, shifting the
+// older ones up. With RotateCompress the file is only renamed, and a
+// background job compresses and shifts it in, so writes aren't held up.
+func (f *rotatingFile) shift() error {
+	if f.maxBackups <= 0 {
+		return remove(f.path)
+	}
+	if !f.config.compress {
+		if err := f.shiftBackups(); err != nil {
+			return err
+		}
+		return os.Rename(f.path, f.backup(1))
+	}
+
+	f.pending++
+	name := f.path + ".pending" + strconv.Itoa(f.pending)
+	if err := os.Rename(f.path, name); err != nil {
+		return err
+	}
+
+	prev, done := f.lastJob, make(chan struct{})
+	f.lastJob = done
+	f.compressing.Add(1)
+	go func() {
+		defer f.compressing.Done()
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		f.reportCompressError(f.compressIn(name))
+	}()
+	return nil
+}
+
+// shiftBackups frees the first backup, compressed or not, by moving every
+// backup up one and removing the oldest
+func (f *rotatingFile) shiftBackups() error {
+	for _, ext := range []string{"", ".gz"} {
+		if err := remove(f.backup(f.maxBackups) + ext); err != nil {
+			return err
+		}
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			if err := rename(f.backup(i)+ext, f.backup(i+1)+ext); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compressIn gzips the pending file name and moves it to the first
+// backup. If compression fails, the file is moved in uncompressed and the
+// error is returned.
+func (f *rotatingFile) compressIn(name string) error {
+	cerr := compressFile(name)
+	ext := ".gz"
+	if cerr != nil {
+		ext = ""
+	}
+	if err := f.shiftBackups(); err != nil {
+		return errors.Join(cerr, err)
+	}
+	return errors.Join(cerr, os.Rename(name+ext, f.backup(1)+ext))
+}
+
+// reportCompressError records err of a compression job for
+// takeCompressError
+func (f *rotatingFile) reportCompressError(err error) {
+	if err == nil {
+		return
+	}
+
+	f.errMu.Lock()
+	defer f.errMu.Unlock()
+	f.compressErr = errors.Join(f.compressErr, fmt.Errorf("llog: compressing rotated file: %w", err))
+}
+
+// takeCompressError returns and clears the errors of finished compression
+// jobs, which are reported by the next Write or Close
+func (f *rotatingFile) takeCompressError() error {
+	f.errMu.Lock()
+	defer f.errMu.Unlock()
+
+	err := f.compressErr
+	f.compressErr = nil
+	return err
+}
+
+// compressFile replaces name with a gzipped name.gz. On failure the
+// uncompressed file is kept.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	var rerr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rerr = f.rotate()
+		if f.file == nil {
+			return 0, rerr
+		}
+	}
+
+	// after a failed rotation p still goes to the file, and the error is
+	// reported along with it
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Join(rerr, err, f.takeCompressError())
+}
+
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	// let in-flight compression finish before the process can exit
+	f.compressing.Wait()
+	return errors.Join(err, f.takeCompressError())
+}
@@ -0,0 +1,172 @@
+package llog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := readFile(t, path); got != "third\n" {
+		t.Fatalf("current = %q", got)
+	}
+	if got := readFile(t, path+".1"); got != "second\n" {
+		t.Fatalf("backup 1 = %q", got)
+	}
+	if got := readFile(t, path+".2"); got != "first\n" {
+		t.Fatalf("backup 2 = %q", got)
+	}
+}
+
+func TestRotatingFileNoLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFile(path, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	w.Write([]byte("second\n"))
+	if got := readFile(t, path); got != "first\nsecond\n" {
+		t.Fatalf("current = %q", got)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Fatalf("rotated without a limit: %v", err)
+	}
+}
+
+func TestRotatingFileRecovers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// a non-empty directory in place of the backup makes rotation fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("first\n"))
+	if _, err := w.Write([]byte("second\n")); err == nil {
+		t.Fatal("failed rotation reported no error")
+	}
+	if got := readFile(t, path); got != "first\nsecond\n" {
+		t.Fatalf("current after failure = %q", got)
+	}
+
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("third\n")); err != nil {
+		t.Fatalf("write after recovery = %v", err)
+	}
+	if got := readFile(t, path); got != "third\n" {
+		t.Fatalf("current after recovery = %q", got)
+	}
+	if got := readFile(t, path+".1"); got != "first\nsecond\n" {
+		t.Fatalf("backup = %q", got)
+	}
+}
+
+// readGzip returns the decompressed contents of the gzip file name
+func readGzip(t *testing.T, name string) string {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingFile(path, 10, 2, RotateCompress())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, path); got != "third\n" {
+		t.Fatalf("current = %q", got)
+	}
+	if got := readGzip(t, path+".1.gz"); got != "second\n" {
+		t.Fatalf("backup 1 = %q", got)
+	}
+	if got := readGzip(t, path+".2.gz"); got != "first\n" {
+		t.Fatalf("backup 2 = %q", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("files left behind: %v", entries)
+	}
+}
+
+func TestRotatingFileCompressError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFile(path, 10, 1, RotateCompress())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a directory in place of the temporary gzip file makes compression fail
+	if err := os.Mkdir(path+".pending1.gz.tmp", 0755); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("first\n"))
+	w.Write([]byte("second\n"))
+	if err := w.Close(); err == nil || !strings.Contains(err.Error(), "compressing") {
+		t.Fatalf("Close = %v, want the compression error", err)
+	}
+	if got := readFile(t, path+".1"); got != "first\n" {
+		t.Fatalf("uncompressed backup = %q", got)
+	}
+}
@@ -0,0 +1,148 @@
+package llog
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// timeRotatingFile is a file that is switched every interval
+type timeRotatingFile struct {
+	dir      string
+	layout   string
+	interval time.Duration
+	config   rotateConfig
+
+	mu   sync.Mutex
+	file *os.File
+	next time.Time
+}
+
+// NewTimeRotatingFile returns a writer appending to a file named by
+// formatting the start of the current interval with the last element of
+// pathPattern, a time layout such as "app-2006-01-02.log"; the directory
+// part is used as is. Intervals are aligned to local
+// midnight, so a 24h interval rolls over daily. A process started partway
+// through an interval appends to that interval's file. Old files are left
+// on disk unless RotateMaxAge is given.
+func NewTimeRotatingFile(pathPattern string, interval time.Duration, opts ...RotateOption) (io.WriteCloser, error) {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	dir, layout := filepath.Split(pathPattern)
+	f := &timeRotatingFile{
+		dir:      dir,
+		layout:   layout,
+		interval: interval,
+	}
+	for _, opt := range opts {
+		opt(&f.config)
+	}
+
+	if err := f.open(nowFunc()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// periodStart returns the start of the interval holding t, in local time
+func (f *timeRotatingFile) periodStart(t time.Time) time.Time {
+	_, offset := t.Zone()
+	d := time.Duration(offset) * time.Second
+	return t.Add(d).Truncate(f.interval).Add(-d)
+}
+
+// open switches to the file of the interval holding now. The current file
+// is only closed once the new one is open, so after a failure writes keep
+// going to it and the switch is retried on the next write.
+func (f *timeRotatingFile) open(now time.Time) error {
+	start := f.periodStart(now)
+	name := filepath.Join(f.dir, start.Format(f.layout))
+
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	old := f.file
+	f.file = file
+	f.next = start.Add(f.interval)
+	if f.config.maxAge > 0 {
+		f.prune(now, name)
+	}
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// prune removes files matching the pattern whose period is too old
+func (f *timeRotatingFile) prune(now time.Time, current string) {
+	dir := f.dir
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := now.Add(-f.config.maxAge)
+	for _, e := range entries {
+		name := filepath.Join(dir, e.Name())
+		if e.IsDir() || name == current {
+			continue
+		}
+
+		t, err := time.ParseInLocation(f.layout, e.Name(), time.Local)
+		if err == nil && t.Before(cutoff) {
+			os.Remove(name)
+		}
+	}
+}
+
+func (f *timeRotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	var rerr error
+	if now := nowFunc(); !now.Before(f.next) {
+		rerr = f.open(now)
+	}
+
+	// after a failed switch p still goes to the current file, and the
+	// error is reported along with it
+	n, err := f.file.Write(p)
+	return n, errors.Join(rerr, err)
+}
+
+func (f *timeRotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+func (f *timeRotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,72 @@
+package llog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimeRotatingFile(t *testing.T) {
+	now := time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)
+	defer SetClock(nil)
+	SetClock(func() time.Time { return now })
+
+	dir := t.TempDir()
+	w, err := NewTimeRotatingFile(filepath.Join(dir, "app-2006010215.log"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	now = now.Add(29 * time.Minute)
+	w.Write([]byte("second\n"))
+	now = now.Add(time.Minute)
+	if _, err := w.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, filepath.Join(dir, "app-2024010210.log")); got != "first\nsecond\n" {
+		t.Fatalf("first interval = %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "app-2024010211.log")); got != "third\n" {
+		t.Fatalf("second interval = %q", got)
+	}
+}
+
+func TestTimeRotatingFileRecovers(t *testing.T) {
+	now := time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)
+	defer SetClock(nil)
+	SetClock(func() time.Time { return now })
+
+	dir := t.TempDir()
+	w, err := NewTimeRotatingFile(filepath.Join(dir, "app-2006010215.log"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// a directory in place of the next file makes the switch fail
+	next := filepath.Join(dir, "app-2024010211.log")
+	if err := os.Mkdir(next, 0755); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := w.Write([]byte("first\n")); err == nil {
+		t.Fatal("failed switch reported no error")
+	}
+	if got := readFile(t, filepath.Join(dir, "app-2024010210.log")); got != "first\n" {
+		t.Fatalf("current after failure = %q", got)
+	}
+
+	if err := os.Remove(next); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatalf("write after recovery = %v", err)
+	}
+	if got := readFile(t, next); got != "second\n" {
+		t.Fatalf("next interval after recovery = %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package llog
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampler lets through the first lines of each key, then 1 out of every
+// thereafter. The key is the level, plus the message when byMsg is set.
+// With a window the counts start over once it elapses.
+type sampler struct {
+	first      uint64
+	thereafter uint64
+	window     time.Duration
+	byMsg      bool
+
+	mu            sync.Mutex
+	counts        map[sampleKey]uint64
+	windowEnd     time.Time
+	windowDropped uint64
+
+	dropped atomic.Uint64
+}
+
+type sampleKey struct {
+	level Level
+	msg   string
+}
+
+// sample reports whether e passes, and how many lines were dropped in the
+// window that ended with e, if any
+func (s *sampler) sample(e *entry) (bool, uint64) {
+	key := sampleKey{level: e.level}
+	if s.byMsg {
+		key.msg = e.msg
+	}
+
+	s.mu.Lock()
+	var ended uint64
+	if s.window > 0 && !e.time.Before(s.windowEnd) {
+		ended = s.windowDropped
+		s.windowDropped = 0
+		s.windowEnd = e.time.Add(s.window)
+		clear(s.counts)
+	}
+	c := s.counts[key]
+	s.counts[key] = c + 1
+	keep := c < s.first || s.thereafter > 0 && (c-s.first+1)%s.thereafter == 0
+	if !keep {
+		s.windowDropped++
+	}
+	s.mu.Unlock()
+
+	if !keep {
+		s.dropped.Add(1)
+	}
+	return keep, ended
+}
+
+// WithSampling returns a logger that prints only the first of every n
+// lines at each level, to stop a hot loop from flooding the output.
+// Fatal lines are never sampled. The sampler is shared by all loggers
+// derived from the returned one, so a burst spread over several of them
+// is still bounded collectively. A value of n below 2 disables sampling.
+func (l *Logger) WithSampling(n int) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.sampler = nil
+	if n > 1 {
+		clone.sampler = &sampler{
+			first:      1,
+			thereafter: uint64(n),
+			counts:     make(map[sampleKey]uint64),
+		}
+	}
+	return clone
+}
+
+// WithSampleFirst returns a logger that, per level and message, prints
+// the first lines arriving within each window in full, then only 1 out of
+// every thereafter, so a burst stays visible without flooding the output.
+// A thereafter below 1 drops everything past first. A window that isn't
+// positive defaults to one second.
+//
+// When a window in which lines were dropped is over, the next line is
+// preceded by a warning reporting how many. Fatal lines are never sampled,
+// and the sampler is shared like the one of WithSampling.
+func (l *Logger) WithSampleFirst(first, thereafter int, window time.Duration) *Logger {
+	if l == nil {
+		return nil
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+
+	clone := l.Clone()
+	clone.sampler = &sampler{
+		first:      uint64(max(first, 0)),
+		thereafter: uint64(max(thereafter, 0)),
+		window:     window,
+		byMsg:      true,
+		counts:     make(map[sampleKey]uint64),
+	}
+	return clone
+}
+
+// emitSampledOut warns that n lines were dropped, if l prints warnings
+func (l *Logger) emitSampledOut(n uint64) {
+	if !l.enabled(LevelWarn) {
+		return
+	}
+	l.emit(&entry{
+		level: LevelWarn,
+		msg:   "sampled out " + strconv.FormatUint(n, 10) + " lines",
+		time:  nowFunc(),
+	})
+}
+
+// SampledOut returns how many lines the sampler of l has dropped so far,
+// so a periodic line can report them
+func (l *Logger) SampledOut() uint64 {
+	if l == nil || l.sampler == nil {
+		return 0
+	}
+	return l.sampler.dropped.Load()
+}
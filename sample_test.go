@@ -0,0 +1,58 @@
+package llog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithSampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).WithTimeFormat("").WithSampling(3)
+	for i := 0; i < 7; i++ {
+		l.Infof("line %d", i)
+	}
+	if got, want := buf.String(), "[I]line 0\n[I]line 3\n[I]line 6\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+	if got := l.SampledOut(); got != 4 {
+		t.Fatalf("SampledOut() = %d", got)
+	}
+}
+
+func TestWithSampleFirst(t *testing.T) {
+	defer SetClock(nil)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf)).WithTimeFormat("").WithSampleFirst(2, 0, time.Second)
+	for i := 0; i < 5; i++ {
+		l.Error("flood")
+	}
+	now = now.Add(time.Second)
+	l.Error("after")
+
+	want := "[E]flood\n[E]flood\n[W]sampled out 3 lines\n[E]after\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestSampledOutWarningHonorsLevel(t *testing.T) {
+	defer SetClock(nil)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf), OptionLevel(LevelError)).WithSampleFirst(1, 0, time.Second)
+	l.Error("flood")
+	l.Error("flood")
+	now = now.Add(time.Second)
+	l.Error("after")
+
+	if strings.Contains(buf.String(), "[W]") {
+		t.Fatalf("warning printed at LevelError: %q", buf.String())
+	}
+}
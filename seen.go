@@ -0,0 +1,54 @@
+package llog
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// levelSeen tracks the most severe level logged, shared by clones
+type levelSeen struct {
+	level atomic.Int64
+}
+
+func newLevelSeen() *levelSeen {
+	s := &levelSeen{}
+	s.level.Store(math.MaxInt)
+	return s
+}
+
+func (s *levelSeen) observe(level Level) {
+	for {
+		cur := s.level.Load()
+		if int64(level) >= cur || s.level.CompareAndSwap(cur, int64(level)) {
+			return
+		}
+	}
+}
+
+// HighestLevelSeen returns the most severe level logged by l or any logger
+// sharing its state, such as every logger derived from it, for CLI tools
+// setting their exit status:
+//
+//	if llog.HighestLevelSeen() <= llog.LevelError {
+//		os.Exit(1)
+//	}
+//
+// Lines count once they pass the level check, even if sampled or
+// filtered out later, or skipped because the output is io.Discard.
+// Before any line it returns math.MaxInt, which is less severe than every
+// level.
+func (l *Logger) HighestLevelSeen() Level {
+	if l == nil {
+		return math.MaxInt
+	}
+	return Level(l.seen.level.Load())
+}
+
+// ResetHighestLevelSeen forgets the levels seen so far by l and the loggers
+// sharing its state
+func (l *Logger) ResetHighestLevelSeen() {
+	if l == nil {
+		return
+	}
+	l.seen.level.Store(math.MaxInt)
+}
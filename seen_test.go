@@ -0,0 +1,46 @@
+package llog
+
+import (
+	"io"
+	"math"
+	"testing"
+)
+
+func TestHighestLevelSeen(t *testing.T) {
+	l := New(OptionOutput(nopWriter{}))
+	if got := l.HighestLevelSeen(); got != math.MaxInt {
+		t.Fatalf("before logging = %d", got)
+	}
+
+	l.Info("a")
+	l.Debug("below the level")
+	if got := l.HighestLevelSeen(); got != LevelInfo {
+		t.Fatalf("after Info = %d", got)
+	}
+
+	l.WithTag("child").Error("b")
+	l.Warn("c")
+	if got := l.HighestLevelSeen(); got != LevelError {
+		t.Fatalf("after Error = %d", got)
+	}
+
+	l.ResetHighestLevelSeen()
+	if got := l.HighestLevelSeen(); got != math.MaxInt {
+		t.Fatalf("after reset = %d", got)
+	}
+}
+
+func TestHighestLevelSeenDiscard(t *testing.T) {
+	l := New(OptionOutput(io.Discard))
+	if l.Enabled(LevelError) {
+		t.Fatal("Enabled is true for io.Discard")
+	}
+	if got := l.HighestLevelSeen(); got != math.MaxInt {
+		t.Fatalf("Enabled counted as seen: %d", got)
+	}
+
+	l.Error("x")
+	if got := l.HighestLevelSeen(); got != LevelError {
+		t.Fatalf("HighestLevelSeen = %d, want %d", got, LevelError)
+	}
+}
@@ -0,0 +1,122 @@
+package llog
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogHandler struct {
+	l      *Logger
+	prefix string
+}
+
+// NewSlogHandler returns a slog.Handler that logs through l:
+//
+//	slog.SetDefault(slog.New(llog.NewSlogHandler(llog.Default())))
+//
+// Attributes are rendered as fields, and groups prefix the keys of
+// the attributes within them as "group.key". Fields extracted from the
+// context passed to Handle are included as with WithContext.
+func NewSlogHandler(l *Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarn
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	case level >= slog.LevelDebug:
+		return LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.enabled(slogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.l == nil {
+		return nil
+	}
+
+	fields := ContextFields(ctx)
+	if r.NumAttrs() > 0 {
+		if fields == nil {
+			fields = make(Fields, r.NumAttrs())
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			addSlogAttr(fields, h.prefix, a)
+			return true
+		})
+	}
+
+	l := h.l
+	if len(fields) > 0 {
+		l = l.WithFields(fields)
+	}
+
+	e := entry{
+		level: slogLevel(r.Level),
+		msg:   r.Message,
+		time:  r.Time,
+		pc:    r.PC,
+	}
+	if e.time.IsZero() {
+		// a zero time must be ignored, so stamp the line ourselves
+		e.time = nowFunc()
+	}
+
+	l.process(&e)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := make(Fields, len(attrs))
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{
+		l:      h.l.WithFields(fields),
+		prefix: h.prefix,
+	}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{
+		l:      h.l,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+func addSlogAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addSlogAttr(fields, prefix, ga)
+		}
+		return
+	}
+
+	fields[prefix+a.Key] = a.Value.Any()
+}
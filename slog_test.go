@@ -0,0 +1,35 @@
+package llog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(OptionOutput(&buf), OptionLevel(LevelDebug)).WithTimeFormat("")
+	log := slog.New(NewSlogHandler(l))
+
+	log.With("user", "ann").WithGroup("req").Info("hello", "method", "GET")
+	log.Debug("verbose")
+	if got, want := buf.String(), "[I]user=ann req.method=GET hello\n[D]verbose\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestSlogHandlerZeroTime(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.UTC) })
+
+	var buf bytes.Buffer
+	h := NewSlogHandler(New(OptionOutput(&buf)).WithUTC(true))
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "no time", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "2024/01/02 03:04:05.006 [I]no time\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
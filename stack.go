@@ -0,0 +1,68 @@
+package llog
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+)
+
+// maxStackFrames bounds the frames printed by WithStackTrace
+const maxStackFrames = 32
+
+// pkgPrefix is the function name prefix of this package, used to trim
+// its own frames from stack traces
+var pkgPrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndexByte(name, '/')
+	return name[:slash+1+strings.IndexByte(name[slash+1:], '.')+1]
+}()
+
+// captureStack returns the current goroutine's stack without the frames
+// inside llog, each line indented by a tab
+func captureStack() string {
+	buf := make([]byte, 16<<10)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	// skip the "goroutine N [running]:" header
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf = buf[i+1:]
+	}
+
+	var b strings.Builder
+	skipping := true
+	frames := 0
+	for len(buf) > 0 && frames < maxStackFrames {
+		// each frame is a function line followed by a file:line line
+		fn, rest, _ := bytes.Cut(buf, []byte{'\n'})
+		file, rest, _ := bytes.Cut(rest, []byte{'\n'})
+		buf = rest
+
+		if skipping && bytes.HasPrefix(fn, []byte(pkgPrefix)) {
+			continue
+		}
+		skipping = false
+		frames++
+
+		b.WriteByte('\t')
+		b.Write(fn)
+		b.WriteString("\n\t")
+		b.Write(file)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// WithStackTrace returns a logger that appends a stack trace of the
+// calling goroutine, indented under the message, to every line at
+// minLevel or more severe. At most 32 frames are printed.
+func (l *Logger) WithStackTrace(minLevel Level) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.stackTrace = true
+	clone.stackLevel = minLevel
+	return clone
+}
@@ -1,101 +1,611 @@
 package llog
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-var std *Logger
+// defaultLogger holds the logger behind the package functions
+var defaultLogger atomic.Pointer[Logger]
 
 func init() {
-	std = &Logger{
-		out: &mutexWriter{
-			Writer: os.Stderr,
-		},
-	}
+	defaultLogger.Store(New())
+}
+
+// std returns the current default logger
+func std() *Logger {
+	return defaultLogger.Load()
 }
 
+// Default returns the logger behind the package functions. The Set
+// functions replace it with a reconfigured copy rather than changing it,
+// so a logger returned earlier doesn't see later calls such as SetLevel
+// or SetOutput; call Default again after reconfiguring.
 func Default() *Logger {
-	return std
+	return std()
+}
+
+// SetDefault makes l the logger behind the package functions, so a logger
+// configured with the With methods can be installed in one step. The swap
+// is atomic and safe while other goroutines log; each call uses either the
+// old or the new logger. A nil l restores the defaults, like Reset.
+func SetDefault(l *Logger) {
+	if l == nil {
+		l = New()
+	}
+	defaultLogger.Store(l)
+}
+
+// Reset restores the std logger to its defaults: stderr output, info
+// level, no tag and no file and line. It is intended for tests; loggers
+// already derived from std keep their configuration.
+func Reset() {
+	defaultLogger.Store(New())
+}
+
+// updateDefault replaces the default logger with a clone of it changed by
+// fn. A concurrent update makes the swap fail, in which case fn is applied
+// again to a fresh clone, so no update is lost.
+//
+// The Set functions go through it rather than changing std in place, so
+// they are safe to call while other goroutines log: each line uses either
+// the old or the new configuration. A logger obtained from Default or the
+// With functions before the call keeps the old one.
+func updateDefault(fn func(l *Logger)) {
+	for {
+		old := defaultLogger.Load()
+		clone := old.Clone()
+		fn(clone)
+		if defaultLogger.CompareAndSwap(old, clone) {
+			return
+		}
+		clone.Release()
+	}
+}
+
+// Capture redirects the output of the std logger to a buffer until the
+// returned function is called, for tests asserting on logged lines. Read
+// the buffer only once logging is done, as it isn't safe for concurrent use.
+func Capture() (*bytes.Buffer, func()) {
+	buf := new(bytes.Buffer)
+	var prev *mutexWriter
+	updateDefault(func(l *Logger) {
+		prev = l.out
+		l.out = newMutexWriter(buf)
+	})
+	return buf, func() {
+		updateDefault(func(l *Logger) {
+			l.out = prev
+		})
+	}
 }
 
+// SetTag replaces the tag of std
 func SetTag(tag string) {
-	std.tag = tag
+	updateDefault(func(l *Logger) {
+		l.setTags(singleTag(tag))
+	})
 }
 
 func SetOutput(out io.Writer) {
-	std.out = &mutexWriter{
-		Writer: out,
-	}
+	w := newMutexWriter(out)
+	updateDefault(func(l *Logger) {
+		l.out = w
+	})
 }
 
-func SetLevelString(s string) {
-	std.setLevelString(s)
+func SetLevelString(s string) error {
+	level, err := parseLevel(s)
+	if err != nil {
+		return err
+	}
+	SetLevel(level)
+	return nil
 }
 
+// SetLevel changes the level of std
 func SetLevel(level Level) {
-	std.setLevel(level)
+	updateDefault(func(l *Logger) {
+		l.setLevel(level)
+	})
 }
 
+// WithTemporaryLevel changes the level of std until restore is called
+func WithTemporaryLevel(level Level) (restore func()) {
+	var prev Level
+	updateDefault(func(l *Logger) {
+		prev = l.Level()
+		l.setLevel(level)
+	})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			SetLevel(prev)
+		})
+	}
+}
+
+// DefaultLevelEnv is the environment variable read by FromEnv
+const DefaultLevelEnv = "LLOG_LEVEL"
+
+// SetLevelFromEnv sets the level of std from the environment variable key,
+// parsed like SetLevelString. An unset or empty variable leaves the level
+// unchanged and returns nil.
+func SetLevelFromEnv(key string) error {
+	s, ok := os.LookupEnv(key)
+	if !ok || s == "" {
+		return nil
+	}
+	if err := SetLevelString(s); err != nil {
+		return fmt.Errorf("%w in $%s", err, key)
+	}
+	return nil
+}
+
+// FromEnv sets the level of std from $LLOG_LEVEL
+func FromEnv() error {
+	return SetLevelFromEnv(DefaultLevelEnv)
+}
+
+// SetFileAndLine toggles the caller of std
 func SetFileAndLine(included bool) {
-	std.fileAndLine = included
+	updateDefault(func(l *Logger) {
+		l.fileAndLine = included
+	})
+}
+
+func SetTimeFormat(layout string) {
+	updateDefault(func(l *Logger) {
+		l.timeFormat = layout
+	})
+}
+
+func SetUTC(utc bool) {
+	updateDefault(func(l *Logger) {
+		l.utc = utc
+	})
+}
+
+func SetShortCaller(short bool) {
+	updateDefault(func(l *Logger) {
+		l.shortCaller = short
+	})
+}
+
+func CommandLogger(cmd *exec.Cmd, infoLevel, errLevel Level, tag string) io.Closer {
+	return std().CommandLogger(cmd, infoLevel, errLevel, tag)
+}
+
+func AddHook(hook Hook) {
+	updateDefault(func(l *Logger) {
+		l.AddHook(hook)
+	})
+}
+
+func WithHook(hook Hook) *Logger {
+	return std().WithHook(hook)
+}
+
+func HighestLevelSeen() Level {
+	return std().HighestLevelSeen()
+}
+
+func ResetHighestLevelSeen() {
+	std().ResetHighestLevelSeen()
+}
+
+func Enabled(level Level) bool {
+	return std().Enabled(level)
 }
 
 func WithTag(tag string) *Logger {
-	return std.WithTag(tag)
+	return std().WithTag(tag)
+}
+
+func WithHeaderStyle(style HeaderStyle) *Logger {
+	return std().WithHeaderStyle(style)
+}
+
+func WithMultilinePrefix(enabled bool) *Logger {
+	return std().WithMultilinePrefix(enabled)
+}
+
+func WithMaxMessageLength(n int) *Logger {
+	return std().WithMaxMessageLength(n)
+}
+
+func WithFieldSeparator(sep string) *Logger {
+	return std().WithFieldSeparator(sep)
+}
+
+func WithPrefix(s string) *Logger {
+	return std().WithPrefix(s)
+}
+
+func WithSuffix(s string) *Logger {
+	return std().WithSuffix(s)
+}
+
+func WithTagReplace(tag string) *Logger {
+	return std().WithTagReplace(tag)
+}
+
+func WithTagSeparator(sep string) *Logger {
+	return std().WithTagSeparator(sep)
+}
+
+func WithTerminator(term string) *Logger {
+	return std().WithTerminator(term)
 }
 
 func WithLevel(level Level) *Logger {
-	return std.WithLevel(level)
+	return std().WithLevel(level)
 }
 
 func WithOutput(out io.Writer) *Logger {
-	return std.WithOutput(out)
+	return std().WithOutput(out)
+}
+
+func WithLevelOutput(level Level, out io.Writer) *Logger {
+	return std().WithLevelOutput(level, out)
+}
+
+func WithWriterFunc(fn func(level Level, msg string)) *Logger {
+	return std().WithWriterFunc(fn)
 }
 
 func WithFileAndLine(included bool) *Logger {
-	return std.WithFileAndLine(included)
+	return std().WithFileAndLine(included)
+}
+
+func WithTimeFormat(layout string) *Logger {
+	return std().WithTimeFormat(layout)
+}
+
+func WithUTC(utc bool) *Logger {
+	return std().WithUTC(utc)
+}
+
+func WithShortCaller(short bool) *Logger {
+	return std().WithShortCaller(short)
+}
+
+func WithCallerTrimPrefix(prefix string) *Logger {
+	return std().WithCallerTrimPrefix(prefix)
+}
+
+func WithCallerFilter(skip func(file string) bool) *Logger {
+	return std().WithCallerFilter(skip)
+}
+
+func WithCallerFunc(included bool) *Logger {
+	return std().WithCallerFunc(included)
+}
+
+func WithStackTrace(minLevel Level) *Logger {
+	return std().WithStackTrace(minLevel)
+}
+
+func WithCallerSkip(n int) *Logger {
+	return std().WithCallerSkip(n)
+}
+
+func WithFields(fields Fields) *Logger {
+	return std().WithFields(fields)
+}
+
+func With(fields ...Field) *Logger {
+	return std().With(fields...)
+}
+
+func WithFilter(fn func(level Level, msg string) bool) *Logger {
+	return std().WithFilter(fn)
+}
+
+func WithRedactor(fn func(string) string) *Logger {
+	return std().WithRedactor(fn)
+}
+
+func WithRedactPattern(re *regexp.Regexp, replacement string) *Logger {
+	return std().WithRedactPattern(re, replacement)
+}
+
+func Group(name string) *Logger {
+	return std().Group(name)
+}
+
+func WithHostname() *Logger {
+	return std().WithHostname()
+}
+
+func WithPID() *Logger {
+	return std().WithPID()
+}
+
+func WithError(err error) *Logger {
+	return std().WithError(err)
+}
+
+func WithTarget(out io.Writer, format Format, minLevel Level) *Logger {
+	return std().WithTarget(out, format, minLevel)
+}
+
+func WithErrorChain(err error) *Logger {
+	return std().WithErrorChain(err)
+}
+
+func WithCode(code string) *Logger {
+	return std().WithCode(code)
+}
+
+func WithFormat(format Format) *Logger {
+	return std().WithFormat(format)
+}
+
+func WithColor(mode ColorMode) *Logger {
+	return std().WithColor(mode)
+}
+
+func WithErrorHandler(fn func(error)) *Logger {
+	return std().WithErrorHandler(fn)
+}
+
+func WithContext(ctx context.Context) *Logger {
+	return std().WithContext(ctx)
+}
+
+func WithSampling(n int) *Logger {
+	return std().WithSampling(n)
+}
+
+func WithSampleFirst(first, thereafter int, window time.Duration) *Logger {
+	return std().WithSampleFirst(first, thereafter, window)
+}
+
+func WithDedup(window time.Duration) *Logger {
+	return std().WithDedup(window)
+}
+
+func Log(level Level, v ...any) {
+	if std().enabled(level) {
+		std().output(level, fmt.Sprint(v...))
+	}
+}
+
+func Logf(level Level, format string, v ...any) {
+	if std().enabled(level) {
+		std().output(level, fmt.Sprintf(format, v...))
+	}
 }
 
 func Error(v ...any) {
-	std.output(LevelError, fmt.Sprint(v...))
+	if std().enabled(LevelError) {
+		std().output(LevelError, fmt.Sprint(v...))
+	}
 }
 
 func Errorf(format string, v ...any) {
-	std.output(LevelError, fmt.Sprintf(format, v...))
+	if std().enabled(LevelError) {
+		std().output(LevelError, fmt.Sprintf(format, v...))
+	}
 }
 
 func Warn(v ...any) {
-	std.output(LevelWarning, fmt.Sprint(v...))
+	if std().enabled(LevelWarn) {
+		std().output(LevelWarn, fmt.Sprint(v...))
+	}
 }
 
 func Warnf(format string, v ...any) {
-	std.output(LevelWarning, fmt.Sprintf(format, v...))
+	if std().enabled(LevelWarn) {
+		std().output(LevelWarn, fmt.Sprintf(format, v...))
+	}
+}
+
+func Warning(v ...any) {
+	if std().enabled(LevelWarn) {
+		std().output(LevelWarn, fmt.Sprint(v...))
+	}
+}
+
+func Warningf(format string, v ...any) {
+	if std().enabled(LevelWarn) {
+		std().output(LevelWarn, fmt.Sprintf(format, v...))
+	}
 }
 
 func Info(v ...any) {
-	std.output(LevelInfo, fmt.Sprint(v...))
+	if std().enabled(LevelInfo) {
+		std().output(LevelInfo, fmt.Sprint(v...))
+	}
 }
 
 func Infof(format string, v ...any) {
-	std.output(LevelInfo, fmt.Sprintf(format, v...))
+	if std().enabled(LevelInfo) {
+		std().output(LevelInfo, fmt.Sprintf(format, v...))
+	}
 }
 
 func Debug(v ...any) {
-	std.output(LevelDebug, fmt.Sprint(v...))
+	if std().enabled(LevelDebug) {
+		std().output(LevelDebug, fmt.Sprint(v...))
+	}
 }
 
 func Debugf(format string, v ...any) {
-	std.output(LevelDebug, fmt.Sprintf(format, v...))
+	if std().enabled(LevelDebug) {
+		std().output(LevelDebug, fmt.Sprintf(format, v...))
+	}
+}
+
+func Trace(v ...any) {
+	if std().enabled(LevelTrace) {
+		std().output(LevelTrace, fmt.Sprint(v...))
+	}
+}
+
+func Tracef(format string, v ...any) {
+	if std().enabled(LevelTrace) {
+		std().output(LevelTrace, fmt.Sprintf(format, v...))
+	}
+}
+
+func ErrorIf(cond bool, v ...any) {
+	if cond && std().enabled(LevelError) {
+		std().output(LevelError, fmt.Sprint(v...))
+	}
+}
+
+func ErrorfIf(cond bool, format string, v ...any) {
+	if cond && std().enabled(LevelError) {
+		std().output(LevelError, fmt.Sprintf(format, v...))
+	}
+}
+
+func ErrorFunc(fn func() string) {
+	std().outputFunc(LevelError, fn)
+}
+
+func WarnIf(cond bool, v ...any) {
+	if cond && std().enabled(LevelWarn) {
+		std().output(LevelWarn, fmt.Sprint(v...))
+	}
+}
+
+func WarnfIf(cond bool, format string, v ...any) {
+	if cond && std().enabled(LevelWarn) {
+		std().output(LevelWarn, fmt.Sprintf(format, v...))
+	}
+}
+
+func WarnFunc(fn func() string) {
+	std().outputFunc(LevelWarn, fn)
+}
+
+func InfoIf(cond bool, v ...any) {
+	if cond && std().enabled(LevelInfo) {
+		std().output(LevelInfo, fmt.Sprint(v...))
+	}
+}
+
+func InfofIf(cond bool, format string, v ...any) {
+	if cond && std().enabled(LevelInfo) {
+		std().output(LevelInfo, fmt.Sprintf(format, v...))
+	}
+}
+
+func InfoFunc(fn func() string) {
+	std().outputFunc(LevelInfo, fn)
+}
+
+func DebugIf(cond bool, v ...any) {
+	if cond && std().enabled(LevelDebug) {
+		std().output(LevelDebug, fmt.Sprint(v...))
+	}
+}
+
+func DebugfIf(cond bool, format string, v ...any) {
+	if cond && std().enabled(LevelDebug) {
+		std().output(LevelDebug, fmt.Sprintf(format, v...))
+	}
+}
+
+func DebugFunc(fn func() string) {
+	std().outputFunc(LevelDebug, fn)
+}
+
+func TraceIf(cond bool, v ...any) {
+	if cond && std().enabled(LevelTrace) {
+		std().output(LevelTrace, fmt.Sprint(v...))
+	}
+}
+
+func TracefIf(cond bool, format string, v ...any) {
+	if cond && std().enabled(LevelTrace) {
+		std().output(LevelTrace, fmt.Sprintf(format, v...))
+	}
+}
+
+func TraceFunc(fn func() string) {
+	std().outputFunc(LevelTrace, fn)
+}
+
+// ErrorLazy is an alias of ErrorFunc
+func ErrorLazy(fn func() string) {
+	std().outputFunc(LevelError, fn)
+}
+
+// WarnLazy is an alias of WarnFunc
+func WarnLazy(fn func() string) {
+	std().outputFunc(LevelWarn, fn)
+}
+
+// InfoLazy is an alias of InfoFunc
+func InfoLazy(fn func() string) {
+	std().outputFunc(LevelInfo, fn)
+}
+
+// DebugLazy is an alias of DebugFunc
+func DebugLazy(fn func() string) {
+	std().outputFunc(LevelDebug, fn)
+}
+
+// TraceLazy is an alias of TraceFunc
+func TraceLazy(fn func() string) {
+	std().outputFunc(LevelTrace, fn)
+}
+
+func Print(v ...any) {
+	if std().enabled(LevelInfo) {
+		std().output(LevelInfo, fmt.Sprint(v...))
+	}
+}
+
+func Println(v ...any) {
+	if std().enabled(LevelInfo) {
+		s := fmt.Sprintln(v...)
+		std().output(LevelInfo, s[:len(s)-1])
+	}
+}
+
+func Printf(format string, v ...any) {
+	if std().enabled(LevelInfo) {
+		std().output(LevelInfo, fmt.Sprintf(format, v...))
+	}
 }
 
 func Fatal(v ...any) {
-	std.output(LevelError, fmt.Sprint(v...))
-	os.Exit(1)
+	if std().enabled(LevelFatal) {
+		std().output(LevelFatal, fmt.Sprint(v...))
+	}
+	std().exit()
 }
 
 func Fatalf(format string, v ...any) {
-	std.output(LevelError, fmt.Sprintf(format, v...))
-	os.Exit(1)
+	if std().enabled(LevelFatal) {
+		std().output(LevelFatal, fmt.Sprintf(format, v...))
+	}
+	std().exit()
+}
+
+func Panic(v ...any) {
+	s := fmt.Sprint(v...)
+	std().output(LevelError, s)
+	std().Flush()
+	panic(s)
+}
+
+func Panicf(format string, v ...any) {
+	s := fmt.Sprintf(format, v...)
+	std().output(LevelError, s)
+	std().Flush()
+	panic(s)
 }
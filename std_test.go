@@ -0,0 +1,134 @@
+package llog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetLevelFromEnv(t *testing.T) {
+	defer Reset()
+	const key = "LLOG_TEST_LEVEL"
+
+	tests := []struct {
+		name    string
+		value   string
+		set     bool
+		want    Level
+		wantErr bool
+	}{
+		{name: "unset", want: LevelInfo},
+		{name: "empty", set: true, want: LevelInfo},
+		{name: "valid", value: "debug", set: true, want: LevelDebug},
+		{name: "case insensitive", value: "WARN", set: true, want: LevelWarn},
+		{name: "invalid", value: "loud", set: true, want: LevelInfo, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Reset()
+			if tt.set {
+				t.Setenv(key, tt.value)
+			}
+			err := SetLevelFromEnv(key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetLevelFromEnv() = %v, want error %v", err, tt.wantErr)
+			}
+			if got := Default().Level(); got != tt.want {
+				t.Fatalf("level = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	defer Reset()
+	t.Setenv(DefaultLevelEnv, "trace")
+
+	if err := FromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if got := Default().Level(); got != LevelTrace {
+		t.Fatalf("level = %d, want %d", got, LevelTrace)
+	}
+}
+
+// logConcurrently logs through the package functions from several
+// goroutines while fn runs, for race tests
+func logConcurrently(fn func()) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				Info("x")
+				WithTag("child").Warn("y")
+			}
+		}()
+	}
+	fn()
+	wg.Wait()
+}
+
+// TestSetTagRace changes the tag of std while other goroutines log; run
+// with -race
+func TestSetTagRace(t *testing.T) {
+	defer Reset()
+	SetOutput(&syncBuffer{})
+
+	logConcurrently(func() {
+		for j := 0; j < 200; j++ {
+			SetTag("a")
+			SetFileAndLine(j%2 == 0)
+			SetLevel(LevelDebug)
+		}
+	})
+	if got := Default().Tag(); got != "a" {
+		t.Fatalf("Tag() = %q", got)
+	}
+}
+
+func TestWithTemporaryLevelDefault(t *testing.T) {
+	defer Reset()
+	SetOutput(nopWriter{})
+	SetLevel(LevelInfo)
+
+	restore := WithTemporaryLevel(LevelDebug)
+	if Default().Level() != LevelDebug {
+		t.Fatalf("level = %v, want debug", Default().Level())
+	}
+	restoreConcurrently(restore)
+	if Default().Level() != LevelInfo {
+		t.Fatalf("level after restore = %v, want info", Default().Level())
+	}
+
+	SetLevel(LevelWarn)
+	restore()
+	if Default().Level() != LevelWarn {
+		t.Fatalf("second restore changed the level to %v", Default().Level())
+	}
+}
+
+func TestSetOutputRace(t *testing.T) {
+	defer Reset()
+	outs := []*syncBuffer{{}, {}}
+	SetOutput(outs[0])
+
+	logConcurrently(func() {
+		for j := 0; j < 200; j++ {
+			SetOutput(outs[j%2])
+		}
+	})
+
+	var lines int
+	for _, out := range outs {
+		s := out.String()
+		if s != "" && !strings.HasSuffix(s, "\n") {
+			t.Fatalf("output ends mid-line: %q", s)
+		}
+		lines += strings.Count(s, "\n")
+	}
+	if want := 4 * 200 * 2; lines != want {
+		t.Fatalf("%d lines written, want %d", lines, want)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package llog
+
+import (
+	"io"
+	"log/syslog"
+)
+
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+// NewSyslogWriter returns a writer sending every line to the syslog daemon
+// at network and addr, or the local one if network is empty, under tag.
+// Lines from a Logger get the syslog severity matching their level, the
+// facility is taken from priority, and plain writes use priority as is.
+func NewSyslogWriter(network, addr, tag string, priority syslog.Priority) (io.WriteCloser, error) {
+	w, err := syslog.Dial(network, addr, priority, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{w: w}, nil
+}
+
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func (w *syslogWriter) WriteLevel(level Level, p []byte) (int, error) {
+	var err error
+	switch m := string(p); {
+	case level <= LevelFatal:
+		err = w.w.Crit(m)
+	case level == LevelError:
+		err = w.w.Err(m)
+	case level == LevelWarn:
+		err = w.w.Warning(m)
+	case level == LevelInfo:
+		err = w.w.Info(m)
+	case level >= LevelDebug:
+		err = w.w.Debug(m)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *syslogWriter) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,32 @@
+package llog
+
+import "io"
+
+// target is an additional output with its own format and level
+type target struct {
+	out      *mutexWriter
+	format   Format
+	minLevel Level
+}
+
+// WithTarget returns a logger that also writes every line at minLevel or
+// more severe to out, encoded in format, next to its regular output:
+//
+//	l = l.WithTarget(file, llog.FormatJSON, llog.LevelInfo)
+//
+// Lines still have to pass the logger's own level first. Each target
+// encodes the line again, so every target adds roughly the cost of one more
+// logger call in formatting time.
+func (l *Logger) WithTarget(out io.Writer, format Format, minLevel Level) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	clone := l.Clone()
+	clone.targets = append(l.targets[:len(l.targets):len(l.targets)], target{
+		out:      newMutexWriter(out),
+		format:   format,
+		minLevel: minLevel,
+	})
+	return clone
+}
@@ -0,0 +1,16 @@
+package llog_test
+
+import "github.com/nayotta/llog"
+
+// This file holds logging wrappers for the caller filter tests, which
+// filter out frames from this file by name.
+
+// auditLog is the outer wrapper layer
+func auditLog(l *llog.Logger, msg string) {
+	infoLog(l, "audit: "+msg)
+}
+
+// infoLog is the inner wrapper layer, the one calling the logger
+func infoLog(l *llog.Logger, msg string) {
+	l.Info(msg)
+}
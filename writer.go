@@ -0,0 +1,236 @@
+package llog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// LevelWriter is implemented by writers that want to know the level of
+// each line, such as to map it to a syslog severity. When an output
+// implements it, WriteLevel is called instead of Write.
+type LevelWriter interface {
+	WriteLevel(level Level, p []byte) (int, error)
+}
+
+// writeLevel writes p to w, passing level along if w is a LevelWriter
+func writeLevel(w io.Writer, level Level, p []byte) (int, error) {
+	if lw, ok := w.(LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.Write(p)
+}
+
+// logWriter adapts a Logger to io.Writer at a fixed level
+type logWriter struct {
+	l     *Logger
+	level Level
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	s := string(p)
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	if !w.l.enabled(w.level) {
+		return len(p), nil
+	}
+
+	var pc uintptr
+	if w.l.fileAndLine || w.l.callerFunc {
+		pc = writerCallerPC(w.l.callerSkip)
+	}
+	w.l.outputPC(w.level, s, pc)
+	return len(p), nil
+}
+
+// lineWriter adapts a Logger to io.Writer at a fixed level, logging each
+// line of the written bytes separately. A trailing partial line is held
+// until it is completed or the writer is flushed.
+type lineWriter struct {
+	l     *Logger
+	level Level
+
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if !w.l.enabled(w.level) {
+		return len(p), nil
+	}
+
+	var pc uintptr
+	if w.l.fileAndLine || w.l.callerFunc {
+		pc = writerCallerPC(w.l.callerSkip)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := p
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		line := data[:i]
+		if len(w.partial) > 0 {
+			line = append(w.partial, line...)
+			w.partial = w.partial[:0]
+		}
+		w.l.outputPC(w.level, strings.TrimSuffix(string(line), "\r"), pc)
+		data = data[i+1:]
+	}
+	w.partial = append(w.partial, data...)
+	return len(p), nil
+}
+
+// Flush logs the buffered partial line, if any
+func (w *lineWriter) Flush() error {
+	if !w.l.enabled(w.level) {
+		return nil
+	}
+
+	var pc uintptr
+	if w.l.fileAndLine || w.l.callerFunc {
+		pc = writerCallerPC(w.l.callerSkip)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		w.l.outputPC(w.level, string(w.partial), pc)
+		w.partial = w.partial[:0]
+	}
+	return nil
+}
+
+// Close flushes the partial line. The logger is left open.
+func (w *lineWriter) Close() error {
+	return w.Flush()
+}
+
+// DefaultWriter returns an io.Writer logging at LevelInfo that splits the
+// written bytes on newlines, so each line becomes its own entry:
+//
+//	fmt.Fprintln(l.DefaultWriter(), "listening on", addr)
+//
+// A write not ending in a newline is buffered until the line is completed.
+// The writer also has Flush and Close methods logging the buffered rest.
+func (l *Logger) DefaultWriter() io.Writer {
+	return &lineWriter{
+		l:     l,
+		level: LevelInfo,
+	}
+}
+
+// writerPackages are the standard library packages that may sit between a
+// logWriter and the code that logged through it, such as log.Printf
+// reaching Write via fmt and log.(*Logger).output
+var writerPackages = []string{"fmt.", "log.", "io.", "bufio."}
+
+// writerCallerPC returns the pc of the first frame above the Write method
+// outside llog and writerPackages, skipping callerSkip more frames after it
+func writerCallerPC(callerSkip int) uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for i := 0; i < n; i++ {
+		frame, _ := runtime.CallersFrames(pcs[i : i+1]).Next()
+		if !isWriterPackage(frame.Function) {
+			if i+callerSkip < n {
+				return pcs[i+callerSkip]
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+func isWriterPackage(fn string) bool {
+	if strings.HasPrefix(fn, pkgPrefix) {
+		return true
+	}
+	for _, pkg := range writerPackages {
+		if strings.HasPrefix(fn, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// Writer returns an io.Writer that logs every write as one line at level,
+// so libraries expecting a *log.Logger or an io.Writer can log through l:
+//
+//	srv := &http.Server{ErrorLog: log.New(l.Writer(llog.LevelError), "", 0)}
+func (l *Logger) Writer(level Level) io.Writer {
+	return &logWriter{
+		l:     l,
+		level: level,
+	}
+}
+
+type multiWriter struct {
+	writers []io.Writer
+}
+
+// MultiWriter returns a writer that duplicates every write to all writers.
+// Unlike io.MultiWriter, a failing writer doesn't stop the rest from being
+// written; the failures are joined into the returned error, which reaches
+// the logger's error handler.
+func MultiWriter(writers ...io.Writer) io.Writer {
+	return &multiWriter{
+		writers: append([]io.Writer(nil), writers...),
+	}
+}
+
+func (w *multiWriter) Write(p []byte) (int, error) {
+	return w.write(p, func(out io.Writer) (int, error) {
+		return out.Write(p)
+	})
+}
+
+func (w *multiWriter) WriteLevel(level Level, p []byte) (int, error) {
+	return w.write(p, func(out io.Writer) (int, error) {
+		return writeLevel(out, level, p)
+	})
+}
+
+func (w *multiWriter) write(p []byte, write func(io.Writer) (int, error)) (int, error) {
+	var errs []error
+	for i, out := range w.writers {
+		n, err := write(out)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("llog: writer %d (%T): %w", i, out, err))
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+func (w *multiWriter) Flush() error {
+	var errs []error
+	for _, out := range w.writers {
+		if err := flushWriter(out); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *multiWriter) Close() error {
+	var errs []error
+	for _, out := range w.writers {
+		if err := closeWriter(out); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
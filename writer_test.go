@@ -0,0 +1,54 @@
+package llog
+
+import (
+	"bytes"
+	"testing"
+)
+
+// levelRecorder is a LevelWriter recording the level of each line
+type levelRecorder struct {
+	levels []Level
+	plain  int // lines written through Write
+}
+
+func (w *levelRecorder) Write(p []byte) (int, error) {
+	w.plain++
+	return len(p), nil
+}
+
+func (w *levelRecorder) WriteLevel(level Level, p []byte) (int, error) {
+	w.levels = append(w.levels, level)
+	return len(p), nil
+}
+
+func TestLevelWriter(t *testing.T) {
+	rec := &levelRecorder{}
+	l := New(OptionOutput(rec), OptionLevel(LevelDebug))
+	l.Error("a")
+	l.Warn("b")
+	l.Info("c")
+	l.Debug("d")
+
+	want := []Level{LevelError, LevelWarn, LevelInfo, LevelDebug}
+	if len(rec.levels) != len(want) || rec.plain != 0 {
+		t.Fatalf("levels = %v, plain writes = %d", rec.levels, rec.plain)
+	}
+	for i := range want {
+		if rec.levels[i] != want[i] {
+			t.Fatalf("levels = %v, want %v", rec.levels, want)
+		}
+	}
+}
+
+func TestLevelWriterThroughMultiWriter(t *testing.T) {
+	rec := &levelRecorder{}
+	var buf bytes.Buffer
+	New(OptionOutput(MultiWriter(rec, &buf))).Warn("w")
+
+	if len(rec.levels) != 1 || rec.levels[0] != LevelWarn {
+		t.Fatalf("levels = %v", rec.levels)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("[W]w")) {
+		t.Fatalf("plain writer got %q", buf.String())
+	}
+}